@@ -0,0 +1,79 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/yamldoc-go/encoder"
+	"github.com/projectdiscovery/yamldoc-go/encoder/docdiff"
+)
+
+var (
+	oldPath        = flag.String("old", "", "Documentation export (JSON or YAML) of the previous version")
+	newPath        = flag.String("new", "", "Documentation export (JSON or YAML) of the current version")
+	format         = flag.String("format", "markdown", "Output format (markdown, json)")
+	failOnBreaking = flag.Bool("fail-on-breaking", false, "Exit with a non-zero code if breaking changes are found")
+)
+
+func main() {
+	flag.Parse()
+
+	breaking, err := process()
+	if err != nil {
+		log.Fatalf("FAIL: %s\n", err.Error())
+	}
+
+	if breaking && *failOnBreaking {
+		os.Exit(1)
+	}
+}
+
+// process compares the documentation exports and writes the report to stdout
+func process() (bool, error) {
+	if *oldPath == "" || *newPath == "" {
+		return false, errors.New("both -old and -new exports are required")
+	}
+
+	oldDocs, err := load(*oldPath)
+	if err != nil {
+		return false, errors.Wrap(err, "could not load old docs")
+	}
+
+	newDocs, err := load(*newPath)
+	if err != nil {
+		return false, errors.Wrap(err, "could not load new docs")
+	}
+
+	report := docdiff.Compare(oldDocs, newDocs)
+
+	switch *format {
+	case "markdown":
+		fmt.Print(report.Markdown())
+	case "json":
+		data, err := report.JSON()
+		if err != nil {
+			return false, errors.Wrap(err, "could not marshal report")
+		}
+		fmt.Println(string(data))
+	default:
+		return false, fmt.Errorf("unknown format %q", *format)
+	}
+
+	return report.HasBreaking(), nil
+}
+
+func load(path string) ([]*encoder.FileDoc, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return encoder.LoadFileDocs(data)
+}
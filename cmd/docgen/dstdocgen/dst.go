@@ -10,11 +10,13 @@ import (
 	"flag"
 	"fmt"
 	"go/token"
+	"go/types"
 	"log"
 	"os"
 	"path"
 	"path/filepath"
 	"reflect"
+	"strconv"
 	"strings"
 	"text/template"
 	"unicode"
@@ -32,6 +34,8 @@ var (
 	structure   = flag.String("structure", "", "Structure Name to Generate Documentation From")
 	output      = flag.String("output", "", "File to write generated documentation code to")
 	packageName = flag.String("package", "main", "Name of the package for auto-generated code")
+	tagName     = flag.String("tag", "talos", "Secondary struct tag holding encoder options")
+	weight      = flag.Int("weight", 0, "Position of the generated page in the site navigation")
 )
 
 type Doc struct {
@@ -39,6 +43,7 @@ type Doc struct {
 	Package string
 	Title   string
 	Header  string
+	Weight  int
 	File    string
 	Structs []*Struct
 }
@@ -75,6 +80,7 @@ type Appearance struct {
 type Example struct {
 	Name  string `yaml:"name"`
 	Value string `yaml:"value"`
+	File  string `yaml:"file"`
 }
 
 type Field struct {
@@ -85,6 +91,12 @@ type Field struct {
 	Tag        string
 	Note       string
 	EnumFields []string
+
+	// goType is the go expression of the field type used to
+	// decode examples loaded from files.
+	goType string
+	// typ is the checked field type used to validate the examples.
+	typ types.Type
 }
 
 type Text struct {
@@ -92,6 +104,11 @@ type Text struct {
 	Description string     `json:"description"`
 	Examples    []*Example `json:"examples"`
 	Values      []string   `json:"values"`
+	Required    bool       `json:"required"`
+	Default     string     `json:"default"`
+	Priority    int        `json:"priority"`
+	Sensitive   bool       `json:"sensitive"`
+	Style       string     `json:"style"`
 }
 
 func main() {
@@ -129,6 +146,7 @@ func process() error {
 
 	doc := &Doc{
 		Package: *packageName,
+		Weight:  *weight,
 		Name:    *structure,
 		Structs: []*Struct{},
 		File:    *output,
@@ -140,6 +158,10 @@ func process() error {
 	for _, s := range structures {
 		fmt.Printf("generating docs for type: %q\n", s.name)
 
+		if err := resolveStructExampleFiles(s); err != nil {
+			return errors.Wrapf(err, "could not resolve examples for %s", s.name)
+		}
+
 		newStruct := &Struct{
 			name:          s.name,
 			packagePrefix: s.packagePrefix,
@@ -519,6 +541,27 @@ func collectFields(s *structType, collectOpts *collectStructOptions) (fields []*
 			TypeRef:    fieldTypeRef,
 			Text:       parseComment([]byte(documentation)),
 			EnumFields: enumFields,
+			goType:     formatGoType(f.Type, s.packagePrefix),
+			typ:        typeOf(s.pkg, f.Type),
+		}
+		// the yaml tag only holds the key and the yaml.v3 flags, the encoder rejects other options there
+		for _, flag := range strings.Split(yamlTags, ",")[1:] {
+			if !yamlFlags[flag] {
+				log.Printf("field %s: unsupported flag %q in the yaml tag, set it in the %q or %q tag", name, flag, *tagName, "yamldoc")
+			}
+		}
+		for _, options := range []string{tag.Get(*tagName), tag.Get("yamldoc")} {
+			if hasTagOption(options, "secret") {
+				field.Text.Sensitive = true
+			}
+			if comment := tagComment(options); comment != "" {
+				field.Text.Comment = escape(comment)
+			}
+			for _, style := range []string{"literal", "folded", "quoted"} {
+				if hasTagOption(options, style) {
+					field.Text.Style = style
+				}
+			}
 		}
 		fields = append(fields, field)
 	}
@@ -645,6 +688,151 @@ func getFieldType(p interface{}, prefix string, apply bool) string {
 	}
 }
 
+// formatGoType returns the go expression for a field type, stripping
+// the top level pointer so that it can be used with new().
+func formatGoType(p interface{}, prefix string) string {
+	if t, ok := p.(*dst.StarExpr); ok {
+		p = t.X
+	}
+
+	return formatTypeExpr(p, prefix)
+}
+
+// formatTypeExpr returns the go expression for a type.
+func formatTypeExpr(p interface{}, prefix string) string {
+	switch t := p.(type) {
+	case *dst.Ident:
+		if t.Path != "" {
+			return wrapStructName(path.Base(t.Path), t.Name)
+		}
+		if t.Obj != nil && prefix != "" {
+			return wrapStructName(prefix, t.Name)
+		}
+		return t.Name
+	case *dst.MapType:
+		return fmt.Sprintf("map[%s]%s", formatTypeExpr(t.Key, prefix), formatTypeExpr(t.Value, prefix))
+	case *dst.ArrayType:
+		if lit, ok := t.Len.(*dst.BasicLit); ok {
+			return fmt.Sprintf("[%s]%s", lit.Value, formatTypeExpr(t.Elt, prefix))
+		}
+		return "[]" + formatTypeExpr(t.Elt, prefix)
+	case *dst.StarExpr:
+		return "*" + formatTypeExpr(t.X, prefix)
+	case *dst.SelectorExpr:
+		return wrapStructName(formatTypeExpr(t.X, ""), t.Sel.Name)
+	case *dst.InterfaceType:
+		return "interface{}"
+	default:
+		return ""
+	}
+}
+
+// resolveStructExampleFiles loads examples referenced with `file` for the
+// struct and all its fields.
+func resolveStructExampleFiles(s *structType) error {
+	dir, err := sourceDir(s.pkg)
+	if err != nil {
+		return err
+	}
+
+	if err := resolveExampleFiles(s.text, wrapStructName(s.packagePrefix, s.name), lookupType(s.pkg, s.name), dir); err != nil {
+		return err
+	}
+
+	for _, field := range s.fields {
+		if err := resolveExampleFiles(field.Text, field.goType, field.typ, dir); err != nil {
+			return errors.Wrapf(err, "field %s", field.Name)
+		}
+	}
+	return nil
+}
+
+// sourceDir returns the directory containing the source files of a package.
+func sourceDir(pkg *decorator.Package) (string, error) {
+	if pkg == nil || len(pkg.GoFiles) == 0 {
+		return "", errors.New("could not find source files")
+	}
+	return filepath.Dir(pkg.GoFiles[0]), nil
+}
+
+// resolveExampleFiles replaces examples referencing a file with a go
+// expression decoding the embedded contents of the file into goType.
+// The contents are validated against the checked type if it is known.
+//
+// The file path is resolved relative to the directory of the source file.
+func resolveExampleFiles(text *Text, goType string, typ types.Type, dir string) error {
+	for _, example := range text.Examples {
+		if example.File == "" {
+			continue
+		}
+		if example.Value != "" {
+			return fmt.Errorf("example %q has both value and file", example.Name)
+		}
+		if goType == "" {
+			return fmt.Errorf("example %q: unsupported type for file examples", example.Name)
+		}
+
+		file := example.File
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(dir, file)
+		}
+
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return errors.Wrap(err, "could not read example file")
+		}
+
+		var parsed interface{}
+		if err := yaml.Unmarshal(data, &parsed); err != nil {
+			return errors.Wrapf(err, "invalid yaml in example file %s", example.File)
+		}
+		if err := validateExampleKind(parsed, goType); err != nil {
+			return errors.Wrapf(err, "example file %s", example.File)
+		}
+		if typ != nil {
+			if err := validateExample(data, typ); err != nil {
+				return errors.Wrapf(err, "example file %s does not match type %s", example.File, goType)
+			}
+		}
+
+		example.Value = fmt.Sprintf("encoder.MustDecodeExample(new(%s), %s)", goType, quoteExample(string(data)))
+	}
+	return nil
+}
+
+// validateExampleKind checks that the top level yaml node kind of an
+// example is compatible with the go type it is decoded into.
+func validateExampleKind(parsed interface{}, goType string) error {
+	if parsed == nil {
+		return errors.New("example is empty")
+	}
+
+	switch parsed.(type) {
+	case []interface{}:
+		if !strings.HasPrefix(goType, "[") && goType != "interface{}" {
+			return fmt.Errorf("got a sequence for type %s", goType)
+		}
+	case map[interface{}]interface{}:
+		if strings.HasPrefix(goType, "[") {
+			return fmt.Errorf("got a mapping for type %s", goType)
+		}
+	default:
+		if strings.HasPrefix(goType, "[") || strings.HasPrefix(goType, "map[") {
+			return fmt.Errorf("got a scalar for type %s", goType)
+		}
+	}
+	return nil
+}
+
+// quoteExample returns data as a go string literal, preferring raw
+// strings to keep generated code readable.
+func quoteExample(data string) string {
+	if strings.ContainsAny(data, "`\r") {
+		return strconv.Quote(data)
+	}
+	return "`" + data + "`"
+}
+
 // uncommentDecorationNode uncomments comments for a dst node.
 func uncommentDecorationNode(node dst.Node) string {
 	decorations := node.Decorations()
@@ -696,6 +884,41 @@ func formatFieldType(p interface{}, prefix string, apply bool) string {
 	}
 }
 
+// hasTagOption checks if the comma separated struct tag contains the option.
+func hasTagOption(tag, option string) bool {
+	// comment option takes the rest of the tag
+	if i := strings.Index(tag, "comment="); i >= 0 {
+		tag = tag[:i]
+	}
+	for _, part := range strings.Split(tag, ",") {
+		if part == option {
+			return true
+		}
+	}
+	return false
+}
+
+// yamlFlags are the options accepted by yaml.v3 in the `yaml` tag.
+var yamlFlags = map[string]bool{
+	"omitempty": true,
+	"flow":      true,
+	"inline":    true,
+}
+
+// tagComment returns the line comment override set with the comment=text option.
+func tagComment(tag string) string {
+	for {
+		if strings.HasPrefix(tag, "comment=") {
+			return strings.TrimPrefix(tag, "comment=")
+		}
+		i := strings.Index(tag, ",")
+		if i < 0 {
+			return ""
+		}
+		tag = tag[i+1:]
+	}
+}
+
 func escape(value string) string {
 	return strings.TrimSpace(strings.ReplaceAll(
 		strings.ReplaceAll(value, "\"", "\\\""),
@@ -725,6 +948,7 @@ func parseComment(comment []byte) *Text {
 	}
 
 	text.Description = escape(text.Description)
+	text.Default = escape(text.Default)
 	for _, example := range text.Examples {
 		example.Name = escape(example.Name)
 		example.Value = strings.TrimSpace(example.Value)
@@ -784,6 +1008,21 @@ func init() {
 	{{ $docVar }}.Fields[{{ $index }}].Note = "{{ $field.Note }}"
 	{{ $docVar }}.Fields[{{ $index }}].Description = "{{ $field.Text.Description }}"
 	{{ $docVar }}.Fields[{{ $index }}].Comments[encoder.LineComment] = "{{ $field.Text.Comment }}"
+	{{ if $field.Text.Required -}}
+	{{ $docVar }}.Fields[{{ $index }}].Required = true
+	{{ end -}}
+	{{ if $field.Text.Default -}}
+	{{ $docVar }}.Fields[{{ $index }}].Default = "{{ $field.Text.Default }}"
+	{{ end -}}
+	{{ if $field.Text.Priority -}}
+	{{ $docVar }}.Fields[{{ $index }}].Priority = {{ $field.Text.Priority }}
+	{{ end -}}
+	{{ if $field.Text.Sensitive -}}
+	{{ $docVar }}.Fields[{{ $index }}].Sensitive = true
+	{{ end -}}
+	{{ if $field.Text.Style -}}
+	{{ $docVar }}.Fields[{{ $index }}].Style = "{{ $field.Text.Style }}"
+	{{ end -}}
 	{{ if $field.EnumFields -}}
 	{{ $docVar }}.Fields[{{ $index }}].EnumFields = []string{
 	{{ range $value := $field.EnumFields -}}
@@ -811,6 +1050,9 @@ func Get{{ .Name }}Doc() *encoder.FileDoc {
 	return &encoder.FileDoc{
 		Name: "{{ .Name }}",
 		Description: "{{ .Header }}",
+		{{ if .Weight -}}
+		Weight: {{ .Weight }},
+		{{ end -}}
 		Structs: []*encoder.Doc{
 			{{ range $struct := .Structs -}}
 			&{{ $struct.GetEscapedName }}Doc,
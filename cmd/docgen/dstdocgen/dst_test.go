@@ -0,0 +1,160 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dave/dst/decorator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/tools/go/packages"
+)
+
+const pipelineSource = `package pipeline
+
+// Step is a pipeline step.
+type Step struct {
+	// description: |
+	//   Name of the step.
+	Name string ` + "`yaml:\"name\"`" + `
+	// description: |
+	//   Retries of the step.
+	Retries int ` + "`yaml:\"retries\"`" + `
+	// description: |
+	//   Secret name of the step.
+	Secret string ` + "`yaml:\"secret\"`" + `
+	// description: |
+	//   Token of the step.
+	Token string ` + "`yaml:\"token\" talos:\"secret,literal\"`" + `
+}
+
+// Pipeline is a list of steps.
+type Pipeline struct {
+	// description: |
+	//   Steps run in order.
+	// examples:
+	//   - file: testdata/steps.yaml
+	Steps []*Step ` + "`yaml:\"steps\"`" + `
+	// description: |
+	//   Named steps.
+	// examples:
+	//   - file: testdata/named.yaml
+	Named map[string]*Step ` + "`yaml:\"named\"`" + `
+	// description: |
+	//   Default step.
+	// examples:
+	//   - file: testdata/default.yaml
+	Default *Step ` + "`yaml:\"default\"`" + `
+}
+`
+
+// loadPipeline writes the pipeline package with the example files and collects its structs.
+func loadPipeline(t *testing.T, files map[string]string) []*structType {
+	dir := t.TempDir()
+
+	// structures are collected once per process
+	uniqueStructures = make(map[string]struct{})
+
+	files["go.mod"] = "module example.com/pipeline\n\ngo 1.18\n"
+	files["pipeline.go"] = pipelineSource
+
+	for name, data := range files {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(data), 0o600))
+	}
+
+	pkgs, err := decorator.Load(&packages.Config{
+		Dir:  dir,
+		Mode: packages.NeedSyntax | packages.NeedTypesInfo | packages.NeedTypes | packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles,
+	}, ".")
+	require.NoError(t, err)
+	require.Len(t, pkgs, 1)
+
+	main, extra := collectStructsWithOpts(&collectStructOptions{
+		pkg:        pkgs[0],
+		structName: "Pipeline",
+	})
+	require.NotNil(t, main)
+
+	return append([]*structType{main}, extra...)
+}
+
+func TestResolveExampleFiles(t *testing.T) {
+	structs := loadPipeline(t, map[string]string{
+		"testdata/steps.yaml":   "- name: build\n  retries: 2\n- name: test\n",
+		"testdata/named.yaml":   "deploy:\n  name: deploy\n",
+		"testdata/default.yaml": "name: default\n",
+	})
+
+	pipeline := structs[0]
+	require.NoError(t, resolveStructExampleFiles(pipeline))
+
+	require.Len(t, pipeline.fields, 3)
+	assert.Equal(t, "encoder.MustDecodeExample(new([]*Step), `- name: build\n  retries: 2\n- name: test\n`)", pipeline.fields[0].Text.Examples[0].Value)
+	assert.Equal(t, "encoder.MustDecodeExample(new(map[string]*Step), `deploy:\n  name: deploy\n`)", pipeline.fields[1].Text.Examples[0].Value)
+	assert.Equal(t, "encoder.MustDecodeExample(new(Step), `name: default\n`)", pipeline.fields[2].Text.Examples[0].Value)
+}
+
+func TestResolveExampleFilesValidation(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		steps string
+		err   string
+	}{
+		{
+			name:  "unknown field",
+			steps: "- name: build\n  timeout: 10\n",
+			err:   "line 2: field timeout not found in type example.com/pipeline.Step",
+		},
+		{
+			name:  "scalar type",
+			steps: "- name: build\n  retries: many\n",
+			err:   `line 2: cannot decode !!str "many" into int`,
+		},
+		{
+			name:  "kind",
+			steps: "- build\n",
+			err:   "line 1: cannot decode a scalar into example.com/pipeline.Step",
+		},
+	} {
+		structs := loadPipeline(t, map[string]string{
+			"testdata/steps.yaml":   test.steps,
+			"testdata/named.yaml":   "deploy:\n  name: deploy\n",
+			"testdata/default.yaml": "name: default\n",
+		})
+
+		err := resolveStructExampleFiles(structs[0])
+		require.Error(t, err, test.name)
+		assert.Contains(t, err.Error(), test.err, test.name)
+	}
+}
+
+func TestCollectFieldsTagOptions(t *testing.T) {
+	structs := loadPipeline(t, map[string]string{})
+
+	fields := map[string]*Field{}
+
+	for _, s := range structs {
+		if s.name != "Step" {
+			continue
+		}
+
+		for _, field := range s.fields {
+			fields[field.Tag] = field
+		}
+	}
+
+	require.Contains(t, fields, "secret")
+	require.Contains(t, fields, "token")
+
+	// options are read from the flags, not from the key name
+	assert.False(t, fields["secret"].Text.Sensitive)
+	assert.Empty(t, fields["secret"].Text.Style)
+	assert.True(t, fields["token"].Text.Sensitive)
+	assert.Equal(t, "literal", fields["token"].Text.Style)
+}
@@ -0,0 +1,274 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"reflect"
+	"strings"
+
+	"github.com/dave/dst"
+	"github.com/dave/dst/decorator"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// typeOf returns the checked type of a type expression of the package.
+func typeOf(pkg *decorator.Package, expr dst.Expr) types.Type {
+	if pkg == nil || pkg.Decorator == nil || pkg.TypesInfo == nil {
+		return nil
+	}
+
+	node, ok := pkg.Decorator.Ast.Nodes[expr].(ast.Expr)
+	if !ok {
+		return nil
+	}
+
+	return pkg.TypesInfo.TypeOf(node)
+}
+
+// lookupType returns the type declared in the package scope.
+func lookupType(pkg *decorator.Package, name string) types.Type {
+	if pkg == nil || pkg.Types == nil {
+		return nil
+	}
+
+	obj := pkg.Types.Scope().Lookup(name)
+	if obj == nil {
+		return nil
+	}
+
+	return obj.Type()
+}
+
+// validateExample checks that the yaml example decodes into the type the same way
+// as encoder.MustDecodeExample does it, with unknown fields rejected.
+func validateExample(data []byte, t types.Type) error {
+	var node yamlv3.Node
+	if err := yamlv3.Unmarshal(data, &node); err != nil {
+		return err
+	}
+
+	if len(node.Content) == 0 {
+		return fmt.Errorf("example is empty")
+	}
+
+	return validateNode(node.Content[0], t)
+}
+
+// validateNode checks that the yaml node can be decoded into the type.
+//
+//nolint:gocyclo
+func validateNode(node *yamlv3.Node, t types.Type) error {
+	if node.Kind == yamlv3.AliasNode {
+		return validateNode(node.Alias, t)
+	}
+
+	if node.Kind == yamlv3.ScalarNode && node.ShortTag() == "!!null" {
+		return nil
+	}
+
+	if hasCustomDecoding(t) {
+		return nil
+	}
+
+	switch u := t.Underlying().(type) {
+	case *types.Pointer:
+		return validateNode(node, u.Elem())
+	case *types.Interface:
+		return nil
+	case *types.Basic:
+		return validateScalar(node, u, t)
+	case *types.Slice:
+		return validateSequence(node, u.Elem(), t)
+	case *types.Array:
+		return validateSequence(node, u.Elem(), t)
+	case *types.Map:
+		if node.Kind != yamlv3.MappingNode {
+			return nodeError(node, "cannot decode %s into %s", kindName(node), t)
+		}
+
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if err := validateNode(node.Content[i], u.Key()); err != nil {
+				return err
+			}
+
+			if err := validateNode(node.Content[i+1], u.Elem()); err != nil {
+				return err
+			}
+		}
+	case *types.Struct:
+		if node.Kind != yamlv3.MappingNode {
+			return nodeError(node, "cannot decode %s into %s", kindName(node), t)
+		}
+
+		fields := map[string]types.Type{}
+		inlineMap := collectYamlFields(u, fields)
+
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			if key == "<<" {
+				continue
+			}
+
+			field, ok := fields[key]
+			if !ok {
+				if inlineMap != nil {
+					field = inlineMap
+				} else {
+					return nodeError(node.Content[i], "field %s not found in type %s", key, t)
+				}
+			}
+
+			if err := validateNode(node.Content[i+1], field); err != nil {
+				return err
+			}
+		}
+	default:
+		return nodeError(node, "unsupported type %s", t)
+	}
+
+	return nil
+}
+
+func validateSequence(node *yamlv3.Node, elem, t types.Type) error {
+	if node.Kind != yamlv3.SequenceNode {
+		return nodeError(node, "cannot decode %s into %s", kindName(node), t)
+	}
+
+	for _, item := range node.Content {
+		if err := validateNode(item, elem); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateScalar(node *yamlv3.Node, basic *types.Basic, t types.Type) error {
+	if node.Kind != yamlv3.ScalarNode {
+		return nodeError(node, "cannot decode %s into %s", kindName(node), t)
+	}
+
+	tag := node.ShortTag()
+	info := basic.Info()
+
+	var ok bool
+
+	switch {
+	case info&types.IsString != 0:
+		ok = true
+	case info&types.IsBoolean != 0:
+		ok = tag == "!!bool"
+	case info&(types.IsInteger|types.IsFloat) != 0:
+		ok = tag == "!!int" || tag == "!!float"
+	}
+
+	if !ok {
+		return nodeError(node, "cannot decode %s %q into %s", tag, node.Value, t)
+	}
+
+	return nil
+}
+
+// collectYamlFields collects the fields of the struct by their yaml keys, including inlined structs,
+// and returns the value type of an inlined map.
+func collectYamlFields(s *types.Struct, fields map[string]types.Type) types.Type {
+	var inlineMap types.Type
+
+	for i := 0; i < s.NumFields(); i++ {
+		field := s.Field(i)
+		if !field.Exported() && !field.Embedded() {
+			continue
+		}
+
+		tag := reflect.StructTag(s.Tag(i)).Get("yaml")
+		parts := strings.Split(tag, ",")
+
+		if parts[0] == "-" {
+			continue
+		}
+
+		inline := false
+
+		for _, flag := range parts[1:] {
+			if flag == "inline" {
+				inline = true
+			}
+		}
+
+		if inline {
+			switch u := field.Type().Underlying().(type) {
+			case *types.Struct:
+				if m := collectYamlFields(u, fields); m != nil {
+					inlineMap = m
+				}
+			case *types.Pointer:
+				if st, ok := u.Elem().Underlying().(*types.Struct); ok {
+					if m := collectYamlFields(st, fields); m != nil {
+						inlineMap = m
+					}
+				}
+			case *types.Map:
+				inlineMap = u.Elem()
+			}
+
+			continue
+		}
+
+		if !field.Exported() {
+			continue
+		}
+
+		name := parts[0]
+		if name == "" {
+			name = strings.ToLower(field.Name())
+		}
+
+		fields[name] = field.Type()
+	}
+
+	return inlineMap
+}
+
+// hasCustomDecoding checks if the type decodes itself from yaml or text.
+func hasCustomDecoding(t types.Type) bool {
+	if named, ok := t.(*types.Named); ok {
+		if obj := named.Obj(); obj.Pkg() != nil && obj.Pkg().Path() == "time" && obj.Name() == "Duration" {
+			return true
+		}
+	}
+
+	if _, ok := t.(*types.Pointer); !ok {
+		t = types.NewPointer(t)
+	}
+
+	methods := types.NewMethodSet(t)
+
+	for _, name := range []string{"UnmarshalYAML", "UnmarshalText"} {
+		if methods.Lookup(nil, name) != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+func kindName(node *yamlv3.Node) string {
+	switch node.Kind {
+	case yamlv3.SequenceNode:
+		return "a sequence"
+	case yamlv3.MappingNode:
+		return "a mapping"
+	case yamlv3.DocumentNode, yamlv3.ScalarNode, yamlv3.AliasNode:
+	}
+
+	return "a scalar"
+}
+
+func nodeError(node *yamlv3.Node, format string, args ...interface{}) error {
+	return fmt.Errorf("line %d: %s", node.Line, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,187 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/yamldoc-go/encoder"
+	yaml "gopkg.in/yaml.v3"
+)
+
+var (
+	specPath = flag.String("spec", "", "Migrations spec file")
+	docsPath = flag.String("docs", "", "Documentation export (JSON or YAML) used to re-decorate migrated files")
+	rootType = flag.String("type", "", "Documented type of the config root")
+)
+
+// Spec describes the migrations applied by the command.
+type Spec struct {
+	VersionKey string          `yaml:"version-key"`
+	Migrations []MigrationSpec `yaml:"migrations"`
+}
+
+// MigrationSpec describes migration from one version to the next one.
+type MigrationSpec struct {
+	From  string     `yaml:"from"`
+	To    string     `yaml:"to"`
+	Steps []StepSpec `yaml:"steps"`
+}
+
+// StepSpec describes a single migration step, only one of the fields is expected to be set.
+type StepSpec struct {
+	Rename *struct {
+		Path string `yaml:"path"`
+		To   string `yaml:"to"`
+	} `yaml:"rename"`
+	Move *struct {
+		From string `yaml:"from"`
+		To   string `yaml:"to"`
+	} `yaml:"move"`
+	SetDefault *struct {
+		Path  string      `yaml:"path"`
+		Value interface{} `yaml:"value"`
+	} `yaml:"set-default"`
+	ChangeType *struct {
+		Path string `yaml:"path"`
+		Type string `yaml:"type"`
+	} `yaml:"change-type"`
+}
+
+func main() {
+	flag.Parse()
+
+	if err := process(flag.Args()); err != nil {
+		log.Fatalf("FAIL: %s\n", err.Error())
+	}
+}
+
+// process migrates all files passed as arguments in place
+func process(files []string) error {
+	if *specPath == "" {
+		return errors.New("-spec is required")
+	}
+
+	migrations, err := loadSpec(*specPath)
+	if err != nil {
+		return errors.Wrap(err, "could not load spec")
+	}
+
+	var doc *encoder.FileDoc
+
+	if *docsPath != "" {
+		if *rootType == "" {
+			return errors.New("-type is required with -docs")
+		}
+
+		if doc, err = loadDocs(*docsPath, *rootType); err != nil {
+			return errors.Wrap(err, "could not load docs")
+		}
+	}
+
+	for _, file := range files {
+		applied, err := migrations.MigrateFile(file, doc, *rootType)
+		if err != nil {
+			return errors.Wrapf(err, "could not migrate %s", file)
+		}
+
+		if len(applied) == 0 {
+			fmt.Printf("%s: up to date\n", file)
+
+			continue
+		}
+
+		fmt.Printf("%s: migrated %s -> %s\n", file, applied[0].From, applied[len(applied)-1].To)
+	}
+
+	return nil
+}
+
+func loadSpec(path string) (*encoder.Migrations, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var spec Spec
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	if err = decoder.Decode(&spec); err != nil {
+		return nil, err
+	}
+
+	if spec.VersionKey == "" {
+		spec.VersionKey = "version"
+	}
+
+	migrations := encoder.NewMigrations(spec.VersionKey)
+
+	for _, m := range spec.Migrations {
+		steps := make([]encoder.MigrationStep, 0, len(m.Steps))
+
+		for i, s := range m.Steps {
+			step, err := s.build()
+			if err != nil {
+				return nil, errors.Wrapf(err, "migration %s -> %s step %d", m.From, m.To, i)
+			}
+
+			steps = append(steps, step)
+		}
+
+		migrations.Register(m.From, m.To, steps...)
+	}
+
+	return migrations, nil
+}
+
+func (s StepSpec) build() (encoder.MigrationStep, error) {
+	switch {
+	case s.Rename != nil:
+		return encoder.RenameKey(s.Rename.Path, s.Rename.To), nil
+	case s.Move != nil:
+		return encoder.MoveKey(s.Move.From, s.Move.To), nil
+	case s.SetDefault != nil:
+		return encoder.SetDefault(s.SetDefault.Path, s.SetDefault.Value), nil
+	case s.ChangeType != nil:
+		if s.ChangeType.Type == "list" {
+			return encoder.ChangeType(s.ChangeType.Path, encoder.ToSequence), nil
+		}
+
+		return encoder.ChangeType(s.ChangeType.Path, encoder.ToScalarType(s.ChangeType.Type)), nil
+	default:
+		return nil, errors.New("empty step")
+	}
+}
+
+func loadDocs(path, typeName string) (*encoder.FileDoc, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	docs, err := encoder.LoadFileDocs(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// merge all files, so types from different files can be resolved
+	merged := &encoder.FileDoc{}
+	for _, fd := range docs {
+		merged.Structs = append(merged.Structs, fd.Structs...)
+	}
+
+	if merged.Lookup(typeName) == nil {
+		return nil, fmt.Errorf("type %q is not documented", typeName)
+	}
+
+	return merged, nil
+}
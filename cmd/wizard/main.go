@@ -0,0 +1,64 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/yamldoc-go/encoder"
+	"github.com/projectdiscovery/yamldoc-go/encoder/wizard"
+)
+
+var (
+	docsPath = flag.String("docs", "", "Documentation export (JSON or YAML) of the config types")
+	rootType = flag.String("type", "", "Documented type of the config root")
+	output   = flag.String("output", "", "Config file to write, stdout is used if empty")
+)
+
+func main() {
+	flag.Parse()
+
+	if err := process(); err != nil {
+		log.Fatalf("FAIL: %s\n", err.Error())
+	}
+}
+
+// process asks for the config values on stderr and writes the config
+func process() error {
+	if *docsPath == "" || *rootType == "" {
+		return errors.New("both -docs and -type are required")
+	}
+
+	data, err := os.ReadFile(*docsPath)
+	if err != nil {
+		return errors.Wrap(err, "could not read docs")
+	}
+
+	docs, err := encoder.LoadFileDocs(data)
+	if err != nil {
+		return errors.Wrap(err, "could not load docs")
+	}
+
+	w, err := wizard.New(os.Stdin, os.Stderr, *rootType, docs...)
+	if err != nil {
+		return err
+	}
+
+	config, err := w.Encode()
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		_, err = os.Stdout.Write(config)
+
+		return err
+	}
+
+	return os.WriteFile(*output, config, 0o644)
+}
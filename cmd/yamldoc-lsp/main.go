@@ -0,0 +1,55 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/projectdiscovery/yamldoc-go/encoder"
+	"github.com/projectdiscovery/yamldoc-go/encoder/lsp"
+)
+
+var (
+	docsPath = flag.String("docs", "", "Documentation export (JSON or YAML) of the config types")
+	rootType = flag.String("type", "", "Documented type of the config root")
+)
+
+func main() {
+	flag.Parse()
+
+	// stdout is used by the protocol
+	log.SetOutput(os.Stderr)
+
+	if err := process(); err != nil {
+		log.Fatalf("FAIL: %s\n", err.Error())
+	}
+}
+
+// process serves the language server protocol over stdio
+func process() error {
+	if *docsPath == "" || *rootType == "" {
+		return errors.New("both -docs and -type are required")
+	}
+
+	data, err := os.ReadFile(*docsPath)
+	if err != nil {
+		return errors.Wrap(err, "could not read docs")
+	}
+
+	docs, err := encoder.LoadFileDocs(data)
+	if err != nil {
+		return errors.Wrap(err, "could not load docs")
+	}
+
+	server, err := lsp.NewServer(*rootType, docs...)
+	if err != nil {
+		return err
+	}
+
+	return server.Serve(os.Stdin, os.Stdout)
+}
@@ -0,0 +1,186 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package encoder
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// pointerKey identifies a value by its address and type, as a struct
+// and its first field share the address.
+type pointerKey struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+// trackPointer remembers the pointer a collection node is encoded from.
+func (s *encodeState) trackPointer(node *yaml.Node, v reflect.Value) {
+	if s.options.Anchors != AnchorsPointers {
+		return
+	}
+
+	//nolint:exhaustive
+	switch v.Elem().Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		s.origins[node] = pointerKey{ptr: v.Pointer(), typ: v.Type()}
+	}
+}
+
+// addAnchors replaces repeated values with aliases to their first occurrence
+// in document order. Documentation comments are kept only at the anchor.
+func (s *encodeState) addAnchors(root *yaml.Node) {
+	var (
+		definitions = map[interface{}]*yaml.Node{}
+		paths       = map[*yaml.Node][]string{}
+		names       = map[string]struct{}{}
+		hashes      = map[*yaml.Node]string{}
+	)
+
+	alias := func(node *yaml.Node, path []string) *yaml.Node {
+		var id interface{}
+
+		switch s.options.Anchors {
+		case AnchorsPointers:
+			key, ok := s.origins[node]
+			if !ok {
+				return nil
+			}
+
+			id = key
+		case AnchorsIdentical:
+			if (node.Kind != yaml.MappingNode && node.Kind != yaml.SequenceNode) || len(node.Content) == 0 {
+				return nil
+			}
+
+			id = hashNode(node, hashes)
+		case AnchorsDisabled:
+			return nil
+		}
+
+		definition, ok := definitions[id]
+		if !ok || definition == node {
+			definitions[id] = node
+			paths[node] = path
+
+			return nil
+		}
+
+		if definition.Anchor == "" {
+			definition.Anchor = s.anchorName(paths[definition], names)
+		}
+
+		return &yaml.Node{
+			Kind:  yaml.AliasNode,
+			Value: definition.Anchor,
+			Alias: definition,
+		}
+	}
+
+	var walk func(node *yaml.Node, path []string)
+
+	walk = func(node *yaml.Node, path []string) {
+		//nolint:exhaustive
+		switch node.Kind {
+		case yaml.DocumentNode:
+			for _, child := range node.Content {
+				walk(child, path)
+			}
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				key := node.Content[i]
+				childPath := append(path[:len(path):len(path)], key.Value)
+
+				if a := alias(node.Content[i+1], childPath); a != nil {
+					node.Content[i+1] = a
+					key.HeadComment, key.LineComment = "", ""
+
+					continue
+				}
+
+				walk(node.Content[i+1], childPath)
+			}
+		case yaml.SequenceNode:
+			for i, item := range node.Content {
+				childPath := append(path[:len(path):len(path)], strconv.Itoa(i))
+
+				if a := alias(item, childPath); a != nil {
+					node.Content[i] = a
+
+					continue
+				}
+
+				walk(item, childPath)
+			}
+		}
+	}
+
+	walk(root, nil)
+}
+
+var anchorNameRe = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// anchorName returns a unique anchor name for the value at the path.
+func (s *encodeState) anchorName(path []string, names map[string]struct{}) string {
+	var name string
+
+	if s.options.AnchorName != nil {
+		name = s.options.AnchorName(strings.Join(path, "."))
+	} else if len(path) > 0 {
+		name = path[len(path)-1]
+		if _, err := strconv.Atoi(name); err == nil && len(path) > 1 {
+			name = path[len(path)-2] + "-" + name
+		}
+	}
+
+	name = anchorNameRe.ReplaceAllString(name, "-")
+	if name == "" {
+		name = "anchor"
+	}
+
+	candidate := name
+	for i := 2; ; i++ {
+		if _, ok := names[candidate]; !ok {
+			break
+		}
+
+		candidate = fmt.Sprintf("%s-%d", name, i)
+	}
+
+	names[candidate] = struct{}{}
+
+	return candidate
+}
+
+// hashNode returns a canonical representation of the node content ignoring comments.
+func hashNode(node *yaml.Node, hashes map[*yaml.Node]string) string {
+	if hash, ok := hashes[node]; ok {
+		return hash
+	}
+
+	var b strings.Builder
+
+	if node.Kind == yaml.AliasNode {
+		fmt.Fprintf(&b, "*%s", node.Value)
+	} else {
+		fmt.Fprintf(&b, "%d%s:%q[", node.Kind, node.ShortTag(), node.Value)
+
+		for _, child := range node.Content {
+			b.WriteString(hashNode(child, hashes))
+			b.WriteString(",")
+		}
+
+		b.WriteString("]")
+	}
+
+	hashes[node] = b.String()
+
+	return hashes[node]
+}
@@ -0,0 +1,117 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package encoder
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v3"
+)
+
+type Credentials struct {
+	User  string `yaml:"user"`
+	Token string `yaml:"token"`
+}
+
+func (c *Credentials) Doc() *Doc {
+	return &Doc{
+		Comments: [3]string{"", "Credentials used to authenticate."},
+		Fields: []Doc{
+			{Comments: [3]string{"", "User name."}},
+			{Comments: [3]string{"", "Access token."}},
+		},
+	}
+}
+
+type Provider struct {
+	Name        string       `yaml:"name"`
+	Credentials *Credentials `yaml:"credentials"`
+}
+
+type Providers struct {
+	Providers []*Provider       `yaml:"providers"`
+	Default   *Credentials      `yaml:"default"`
+	Labels    map[string]string `yaml:"labels,omitempty"`
+	Tags      map[string]string `yaml:"tags,omitempty"`
+}
+
+func TestAnchors(t *testing.T) {
+	shared := &Credentials{User: "admin", Token: "secret"}
+	in := &Providers{
+		Providers: []*Provider{
+			{Name: "dns", Credentials: shared},
+			{Name: "http", Credentials: shared},
+			{Name: "file", Credentials: &Credentials{User: "admin", Token: "secret"}},
+		},
+		Default: shared,
+		Labels:  map[string]string{"team": "core"},
+		Tags:    map[string]string{"team": "core"},
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		data, err := NewEncoder(in).Encode()
+		require.NoError(t, err)
+
+		assert.NotContains(t, string(data), "&")
+		assert.NotContains(t, string(data), "*")
+	})
+
+	t.Run("pointers", func(t *testing.T) {
+		data, err := NewEncoder(in, WithAnchors(AnchorsPointers)).Encode()
+		require.NoError(t, err)
+
+		out := string(data)
+		assert.Equal(t, 1, strings.Count(out, "credentials: &credentials"), out)
+		assert.Equal(t, 2, strings.Count(out, "*credentials"), out)
+		assert.Contains(t, out, "default: *credentials", out)
+		assert.NotContains(t, out, "*labels", out)
+
+		// documentation is attached to the anchor only
+		assert.Equal(t, 2, strings.Count(out, "# User name."), out)
+
+		var decoded Providers
+		require.NoError(t, yaml.Unmarshal(data, &decoded))
+		assert.Equal(t, in, &decoded)
+	})
+
+	t.Run("identical", func(t *testing.T) {
+		data, err := NewEncoder(in, WithComments(CommentsDisabled), WithAnchors(AnchorsIdentical)).Encode()
+		require.NoError(t, err)
+
+		assert.Equal(t, `providers:
+    - name: dns
+      credentials: &credentials
+        user: admin
+        token: secret
+    - name: http
+      credentials: *credentials
+    - name: file
+      credentials: *credentials
+default: *credentials
+labels: &labels
+    team: core
+tags: *labels
+`, string(data))
+	})
+
+	t.Run("names", func(t *testing.T) {
+		paths := []string{}
+
+		data, err := NewEncoder(in, WithComments(CommentsDisabled), WithAnchors(AnchorsIdentical), WithAnchorNames(func(path string) string {
+			paths = append(paths, path)
+
+			return "shared"
+		})).Encode()
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"providers.0.credentials", "labels"}, paths)
+		assert.Contains(t, string(data), "credentials: &shared\n")
+		assert.Contains(t, string(data), "labels: &shared-2\n")
+		assert.Contains(t, string(data), "tags: *shared-2\n")
+	})
+}
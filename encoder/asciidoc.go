@@ -0,0 +1,122 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package encoder
+
+import (
+	"fmt"
+	"strings"
+)
+
+// EncodeAsciiDoc encodes file documentation as an AsciiDoc document.
+//
+// Types are cross-referenced with native AsciiDoc xrefs, so the document
+// can be used as an Antora page.
+func (fd *FileDoc) EncodeAsciiDoc(opts ...RenderOption) ([]byte, error) {
+	options := newRenderOptions(opts...)
+
+	r := newFileRender(fd)
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "= %s\n", fd.Name)
+
+	if fd.Description != "" {
+		fmt.Fprintf(&b, "\n%s\n", strings.TrimSpace(fd.Description))
+	}
+
+	for _, s := range fd.Structs {
+		r.writeAsciiDocStruct(&b, s, options.encoderOptions())
+	}
+
+	return []byte(b.String()), nil
+}
+
+//nolint:gocyclo
+func (r *fileRender) writeAsciiDocStruct(b *strings.Builder, s *Doc, options *Options) {
+	fmt.Fprintf(b, "\n[[%s]]\n== %s\n", r.structAnchor(s.Type), s.Type)
+
+	if s.Description != "" {
+		fmt.Fprintf(b, "\n%s\n", strings.TrimSpace(s.Description))
+	}
+
+	if len(s.AppearsIn) > 0 {
+		b.WriteString("\nAppears in:\n\n")
+
+		for _, appearance := range s.AppearsIn {
+			fmt.Fprintf(b, "* %s.`%s`\n", r.asciiDocType(appearance.TypeName), appearance.FieldName)
+		}
+	}
+
+	for _, example := range s.Examples {
+		b.WriteString("\n")
+		writeAsciiDocExample(b, renderYaml(example.GetValue(), "", example.GetName(), options))
+	}
+
+	if len(s.PartDefinitions) > 0 {
+		b.WriteString("\nPart Definitions:\n\n")
+
+		for _, value := range s.PartDefinitions {
+			fmt.Fprintf(b, "* `%s` - %s\n", value.Key, value.Value)
+		}
+	}
+
+	if len(s.Fields) > 0 {
+		b.WriteString("\n")
+	}
+
+	for _, field := range s.Fields {
+		fmt.Fprintf(b, "[[%s]]`%s`:: _%s_", r.fieldAnchor(s.Type, field.Name), field.Name, r.asciiDocType(field.Type))
+
+		if attributes := fieldAttributes(field); attributes != "" {
+			fmt.Fprintf(b, " (%s)", attributes)
+		}
+
+		b.WriteString("\n+\n--\n")
+
+		if field.Description != "" {
+			fmt.Fprintf(b, "%s\n", strings.TrimSpace(field.Description))
+		}
+
+		writeAsciiDocValues(b, "Valid values:", field.Values)
+		writeAsciiDocValues(b, "Enum Values:", field.EnumFields)
+
+		if field.Note != "" {
+			fmt.Fprintf(b, "\nNOTE: %s\n", field.Note)
+		}
+
+		for _, example := range fieldExamples(&field, options) {
+			b.WriteString("\n")
+			writeAsciiDocExample(b, renderFieldYaml(&field, example, options))
+		}
+
+		b.WriteString("--\n")
+	}
+
+	if len(s.Values) > 0 {
+		writeAsciiDocValues(b, s.Type+" Valid Values:", s.Values)
+	}
+}
+
+func (r *fileRender) asciiDocType(t string) string {
+	return r.linkTypes(t, func(text, anchor string) string {
+		return fmt.Sprintf("<<%s,%s>>", anchor, text)
+	})
+}
+
+func writeAsciiDocValues(b *strings.Builder, title string, values []string) {
+	if len(values) == 0 {
+		return
+	}
+
+	fmt.Fprintf(b, "\n%s\n\n", title)
+
+	for _, value := range values {
+		fmt.Fprintf(b, "* `%s`\n", value)
+	}
+}
+
+func writeAsciiDocExample(b *strings.Builder, data string) {
+	fmt.Fprintf(b, "[source,yaml]\n----\n%s\n----\n", strings.TrimRight(data, "\n"))
+}
@@ -0,0 +1,31 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package encoder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeAsciiDoc(t *testing.T) {
+	fd := testFileDoc()
+	fd.Structs[0].Fields[2].Note = "internal use only"
+	fd.Structs[1].PartDefinitions = []KeyValue{{Key: "body", Value: "response body"}}
+
+	data, err := fd.EncodeAsciiDoc()
+	require.NoError(t, err)
+
+	out := string(data)
+
+	assert.Contains(t, out, "[[job]]\n== Job\n\nJob is a single job.\n")
+	assert.Contains(t, out, "[[job-name]]`name`:: _string_ (required)\n+\n--\nName of the job\nwith a | pipe\n\n[source,yaml]\n----\n# Name Example\nname: job\n----\n--\n")
+	assert.Contains(t, out, "Valid values:\n\n* `dns`\n* `http`\n")
+	assert.Contains(t, out, "[[job-internal-options]]`internal-options`:: _<<internaloptions,InternalOptions>>_\n+\n--\n\nNOTE: internal use only\n--\n")
+	assert.Contains(t, out, "Appears in:\n\n* <<job,Job>>.`internal-options`\n")
+	assert.Contains(t, out, "Part Definitions:\n\n* `body` - response body\n")
+	assert.NotContains(t, out, "<a href")
+}
@@ -0,0 +1,154 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package encoder
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v3"
+)
+
+// ID implements encoding.TextMarshaler.
+type ID [2]byte
+
+func (id ID) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("id-%d-%d", id[0], id[1])), nil
+}
+
+// Upper implements encoding.TextMarshaler on the pointer receiver.
+type Upper string
+
+func (u *Upper) MarshalText() ([]byte, error) {
+	return []byte(strings.ToUpper(string(*u))), nil
+}
+
+// Window implements yaml.IsZeroer.
+type Window struct {
+	Start int `yaml:"start"`
+	End   int `yaml:"end"`
+}
+
+func (w Window) IsZero() bool {
+	return w.End <= w.Start
+}
+
+// Level implements yaml.Marshaler on the value receiver.
+type Level int
+
+func (l Level) MarshalYAML() (interface{}, error) {
+	return []string{"debug", "info"}[l], nil
+}
+
+type Private struct {
+	value int
+}
+
+type Conformance struct {
+	Duration   time.Duration            `yaml:"duration"`
+	Time       time.Time                `yaml:"time"`
+	TimePtr    *time.Time               `yaml:"time_ptr,omitempty"`
+	IP         net.IP                   `yaml:"ip"`
+	ID         ID                       `yaml:"id"`
+	IDs        map[ID]string            `yaml:"ids,omitempty"`
+	Upper      *Upper                   `yaml:"upper,omitempty"`
+	Window     Window                   `yaml:"window,omitempty"`
+	WindowPtr  *Window                  `yaml:"window_ptr,omitempty"`
+	Level      Level                    `yaml:"level"`
+	Private    Private                  `yaml:"private,omitempty"`
+	Array      [2]int                   `yaml:"array,omitempty"`
+	Interface  interface{}              `yaml:"interface,omitempty"`
+	Node       yaml.Node                `yaml:"node,omitempty"`
+	Ints       map[int]string           `yaml:"ints,omitempty"`
+	Flow       []string                 `yaml:"flow,flow"`
+	Nested     map[string][]interface{} `yaml:"nested,omitempty"`
+	EmptyPtr   *Inline                  `yaml:"empty_ptr"`
+	Inline     `yaml:",inline"`
+	unexported string
+}
+
+type Inline struct {
+	Inlined string `yaml:"inlined"`
+}
+
+// TestConformance compares the encoder output with yaml.Marshal.
+func TestConformance(t *testing.T) {
+	upper := Upper("upper")
+	when := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	var node yaml.Node
+	require.NoError(t, node.Encode(map[string]int{"a": 1}))
+
+	for _, test := range []struct {
+		name  string
+		value interface{}
+	}{
+		{
+			name:  "zero",
+			value: &Conformance{},
+		},
+		{
+			name: "populated",
+			value: &Conformance{
+				Duration:  time.Minute + time.Second,
+				Time:      when,
+				TimePtr:   &when,
+				IP:        net.ParseIP("10.0.0.1"),
+				ID:        ID{1, 2},
+				IDs:       map[ID]string{{3, 4}: "b"},
+				Upper:     &upper,
+				Window:    Window{Start: 1, End: 2},
+				WindowPtr: &Window{Start: 2, End: 1},
+				Level:     1,
+				Private:   Private{value: 1},
+				Array:     [2]int{0, 1},
+				Interface: map[string]interface{}{"key": []int{1, 2}},
+				Node:      node,
+				Ints:      map[int]string{10: "ten", 9: "nine"},
+				Flow:      []string{"a", "b"},
+				Nested:    map[string][]interface{}{"list": {1, "two", nil, true}},
+				Inline:    Inline{Inlined: "inlined"},
+			},
+		},
+		{
+			name:  "scalars",
+			value: []interface{}{1, "1", 1.5, true, nil, "", "multi\nline", "yes", time.Second},
+		},
+		{
+			name:  "text marshaler",
+			value: net.ParseIP("::1"),
+		},
+		{
+			name:  "window omitted",
+			value: map[string]Window{"zero": {}, "set": {End: 1}},
+		},
+	} {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			expected, err := yaml.Marshal(test.value)
+			require.NoError(t, err)
+
+			node, err := NewEncoder(test.value, WithComments(CommentsDisabled)).Marshal()
+			require.NoError(t, err)
+
+			actual, err := yaml.Marshal(node)
+			require.NoError(t, err)
+
+			assert.Equal(t, string(expected), string(actual))
+
+			// undocumented values are encoded the same way with comments enabled
+			actual, err = NewEncoder(test.value).Encode()
+			require.NoError(t, err)
+
+			assert.Equal(t, string(expected), string(actual))
+		})
+	}
+}
@@ -0,0 +1,95 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package encoder
+
+import (
+	"reflect"
+)
+
+// deepCopy returns a copy of the value sharing no pointers, maps or slices with it,
+// so the copy can be modified without affecting the original.
+func deepCopy(v reflect.Value) reflect.Value {
+	return copyValue(v, map[uintptr]reflect.Value{})
+}
+
+//nolint:gocyclo
+func copyValue(v reflect.Value, copied map[uintptr]reflect.Value) reflect.Value {
+	if !v.IsValid() {
+		return v
+	}
+
+	//nolint:exhaustive
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+
+		// keep shared and cyclic references within the copy
+		if res, ok := copied[v.Pointer()]; ok && res.Type() == v.Type() {
+			return res
+		}
+
+		res := reflect.New(v.Type().Elem())
+		copied[v.Pointer()] = res
+		res.Elem().Set(copyValue(v.Elem(), copied))
+
+		return res
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+
+		res := reflect.New(v.Type()).Elem()
+		res.Set(copyValue(v.Elem(), copied))
+
+		return res
+	case reflect.Struct:
+		res := reflect.New(v.Type()).Elem()
+		// unexported fields can't be set by reflection, they are copied shallow
+		res.Set(v)
+
+		for i := 0; i < v.NumField(); i++ {
+			if res.Field(i).CanSet() {
+				res.Field(i).Set(copyValue(v.Field(i), copied))
+			}
+		}
+
+		return res
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+
+		res := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			res.Index(i).Set(copyValue(v.Index(i), copied))
+		}
+
+		return res
+	case reflect.Array:
+		res := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			res.Index(i).Set(copyValue(v.Index(i), copied))
+		}
+
+		return res
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+
+		res := reflect.MakeMapWithSize(v.Type(), v.Len())
+
+		iter := v.MapRange()
+		for iter.Next() {
+			res.SetMapIndex(copyValue(iter.Key(), copied), copyValue(iter.Value(), copied))
+		}
+
+		return res
+	default:
+		return v
+	}
+}
@@ -0,0 +1,426 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package docdiff compares two versions of the documentation and reports
+// configuration changes between them.
+package docdiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/projectdiscovery/yamldoc-go/encoder"
+)
+
+// ChangeKind describes the type of a change.
+type ChangeKind string
+
+const (
+	// StructAdded is reported when a new struct is documented.
+	StructAdded ChangeKind = "struct_added"
+	// StructRemoved is reported when a struct is no longer documented.
+	StructRemoved ChangeKind = "struct_removed"
+	// FieldAdded is reported for new fields.
+	FieldAdded ChangeKind = "field_added"
+	// FieldRemoved is reported for removed fields.
+	FieldRemoved ChangeKind = "field_removed"
+	// FieldRenamed is reported when a removed and an added field look the same.
+	FieldRenamed ChangeKind = "field_renamed"
+	// FieldRetyped is reported when the type of a field changes.
+	FieldRetyped ChangeKind = "field_retyped"
+	// FieldRequired is reported when a field becomes required.
+	FieldRequired ChangeKind = "field_required"
+	// FieldDeprecated is reported when a field description starts mentioning a deprecation.
+	FieldDeprecated ChangeKind = "field_deprecated"
+	// ValuesChanged is reported when the list of valid values changes.
+	ValuesChanged ChangeKind = "values_changed"
+	// EnumFieldsChanged is reported when the list of enum values changes.
+	EnumFieldsChanged ChangeKind = "enum_fields_changed"
+)
+
+// renameThreshold is the minimal similarity score for a removed and an
+// added field to be reported as a rename.
+const renameThreshold = 0.5
+
+var deprecatedRe = regexp.MustCompile(`(?i)\bdeprecated\b`)
+
+// Change is a single difference between two documentation versions.
+type Change struct {
+	Kind     ChangeKind `json:"kind"`
+	Struct   string     `json:"struct"`
+	Field    string     `json:"field,omitempty"`
+	From     string     `json:"from,omitempty"`
+	To       string     `json:"to,omitempty"`
+	Added    []string   `json:"added,omitempty"`
+	Removed  []string   `json:"removed,omitempty"`
+	Breaking bool       `json:"breaking"`
+}
+
+// Path returns the struct and field the change refers to.
+func (c *Change) Path() string {
+	if c.Field == "" {
+		return c.Struct
+	}
+
+	return c.Struct + "." + c.Field
+}
+
+// String returns a human readable change description.
+func (c *Change) String() string {
+	path := "`" + c.Path() + "`"
+
+	switch c.Kind {
+	case StructAdded:
+		return fmt.Sprintf("%s was added", path)
+	case StructRemoved:
+		return fmt.Sprintf("%s was removed", path)
+	case FieldAdded:
+		return fmt.Sprintf("%s was added with type `%s`", path, c.To)
+	case FieldRemoved:
+		return fmt.Sprintf("%s was removed", path)
+	case FieldRenamed:
+		return fmt.Sprintf("`%s.%s` was renamed to `%s`", c.Struct, c.From, c.To)
+	case FieldRetyped:
+		return fmt.Sprintf("%s type changed from `%s` to `%s`", path, c.From, c.To)
+	case FieldRequired:
+		return fmt.Sprintf("%s is now required", path)
+	case FieldDeprecated:
+		return fmt.Sprintf("%s was deprecated", path)
+	case ValuesChanged, EnumFieldsChanged:
+		parts := []string{}
+		if len(c.Added) > 0 {
+			parts = append(parts, "added "+codeList(c.Added))
+		}
+		if len(c.Removed) > 0 {
+			parts = append(parts, "removed "+codeList(c.Removed))
+		}
+		return fmt.Sprintf("%s valid values: %s", path, strings.Join(parts, ", "))
+	default:
+		return fmt.Sprintf("%s changed", path)
+	}
+}
+
+// Report holds all changes between two documentation versions.
+type Report struct {
+	Changes []Change `json:"changes"`
+}
+
+// HasBreaking returns true if the report contains breaking changes.
+func (r *Report) HasBreaking() bool {
+	for _, c := range r.Changes {
+		if c.Breaking {
+			return true
+		}
+	}
+
+	return false
+}
+
+// JSON returns the report as indented JSON.
+func (r *Report) JSON() ([]byte, error) {
+	return json.MarshalIndent(r, "", "  ")
+}
+
+// Markdown returns the report formatted for release notes.
+func (r *Report) Markdown() string {
+	var breaking, deprecated, other []string
+
+	for i := range r.Changes {
+		c := &r.Changes[i]
+
+		switch {
+		case c.Breaking:
+			breaking = append(breaking, c.String())
+		case c.Kind == FieldDeprecated:
+			deprecated = append(deprecated, c.String())
+		default:
+			other = append(other, c.String())
+		}
+	}
+
+	var b strings.Builder
+
+	b.WriteString("## Configuration changes\n")
+
+	if len(r.Changes) == 0 {
+		b.WriteString("\nNo configuration changes.\n")
+	}
+
+	writeSection(&b, "Breaking changes", breaking)
+	writeSection(&b, "Deprecations", deprecated)
+	writeSection(&b, "Other changes", other)
+
+	return b.String()
+}
+
+func writeSection(b *strings.Builder, title string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+
+	fmt.Fprintf(b, "\n### %s\n\n", title)
+
+	for _, item := range items {
+		fmt.Fprintf(b, "- %s\n", item)
+	}
+}
+
+// Compare compares structs documented in two sets of file docs.
+//
+// Structs are matched by type name and fields by their yaml name,
+// fields which were removed and added with the same type and a similar
+// name or description are reported as renamed.
+func Compare(oldDocs, newDocs []*encoder.FileDoc) *Report {
+	report := &Report{Changes: []Change{}}
+
+	oldStructs, oldOrder := collectStructs(oldDocs)
+	newStructs, newOrder := collectStructs(newDocs)
+
+	for _, name := range oldOrder {
+		if _, ok := newStructs[name]; !ok {
+			report.add(Change{Kind: StructRemoved, Struct: name, Breaking: true})
+		}
+	}
+
+	for _, name := range newOrder {
+		oldStruct, ok := oldStructs[name]
+		if !ok {
+			report.add(Change{Kind: StructAdded, Struct: name})
+
+			continue
+		}
+
+		compareFields(report, name, oldStruct, newStructs[name])
+	}
+
+	return report
+}
+
+func (r *Report) add(c Change) {
+	r.Changes = append(r.Changes, c)
+}
+
+func collectStructs(docs []*encoder.FileDoc) (map[string]*encoder.Doc, []string) {
+	structs := map[string]*encoder.Doc{}
+	order := []string{}
+
+	for _, fd := range docs {
+		for _, s := range fd.Structs {
+			if _, ok := structs[s.Type]; ok {
+				continue
+			}
+
+			structs[s.Type] = s
+			order = append(order, s.Type)
+		}
+	}
+
+	return structs, order
+}
+
+//nolint:gocyclo
+func compareFields(report *Report, structName string, oldStruct, newStruct *encoder.Doc) {
+	oldFields := map[string]*encoder.Doc{}
+	for i := range oldStruct.Fields {
+		oldFields[oldStruct.Fields[i].Name] = &oldStruct.Fields[i]
+	}
+
+	newFields := map[string]*encoder.Doc{}
+	for i := range newStruct.Fields {
+		newFields[newStruct.Fields[i].Name] = &newStruct.Fields[i]
+	}
+
+	var removed, added []*encoder.Doc
+
+	for i := range oldStruct.Fields {
+		if _, ok := newFields[oldStruct.Fields[i].Name]; !ok {
+			removed = append(removed, &oldStruct.Fields[i])
+		}
+	}
+
+	for i := range newStruct.Fields {
+		field := &newStruct.Fields[i]
+
+		oldField, ok := oldFields[field.Name]
+		if !ok {
+			added = append(added, field)
+
+			continue
+		}
+
+		compareField(report, structName, oldField, field)
+	}
+
+	renamed := matchRenames(removed, added)
+
+	for _, field := range removed {
+		if to, ok := renamed[field]; ok {
+			report.add(Change{Kind: FieldRenamed, Struct: structName, Field: to.Name, From: field.Name, To: to.Name, Breaking: true})
+			compareField(report, structName, field, to)
+
+			continue
+		}
+
+		report.add(Change{Kind: FieldRemoved, Struct: structName, Field: field.Name, From: field.Type, Breaking: true})
+	}
+
+	matched := map[*encoder.Doc]struct{}{}
+	for _, to := range renamed {
+		matched[to] = struct{}{}
+	}
+
+	for _, field := range added {
+		if _, ok := matched[field]; ok {
+			continue
+		}
+
+		report.add(Change{Kind: FieldAdded, Struct: structName, Field: field.Name, To: field.Type, Breaking: field.Required})
+	}
+}
+
+func compareField(report *Report, structName string, oldField, newField *encoder.Doc) {
+	if oldField.Type != newField.Type {
+		report.add(Change{Kind: FieldRetyped, Struct: structName, Field: newField.Name, From: oldField.Type, To: newField.Type, Breaking: true})
+	}
+
+	if !oldField.Required && newField.Required {
+		report.add(Change{Kind: FieldRequired, Struct: structName, Field: newField.Name, Breaking: true})
+	}
+
+	if !isDeprecated(oldField) && isDeprecated(newField) {
+		report.add(Change{Kind: FieldDeprecated, Struct: structName, Field: newField.Name})
+	}
+
+	if added, removed := diffValues(oldField.Values, newField.Values); len(added)+len(removed) > 0 {
+		report.add(Change{Kind: ValuesChanged, Struct: structName, Field: newField.Name, Added: added, Removed: removed, Breaking: len(removed) > 0})
+	}
+
+	if added, removed := diffValues(oldField.EnumFields, newField.EnumFields); len(added)+len(removed) > 0 {
+		report.add(Change{Kind: EnumFieldsChanged, Struct: structName, Field: newField.Name, Added: added, Removed: removed, Breaking: len(removed) > 0})
+	}
+}
+
+func isDeprecated(field *encoder.Doc) bool {
+	return deprecatedRe.MatchString(field.Description) || deprecatedRe.MatchString(field.Note)
+}
+
+func diffValues(oldValues, newValues []string) (added, removed []string) {
+	oldSet := map[string]struct{}{}
+	for _, v := range oldValues {
+		oldSet[v] = struct{}{}
+	}
+
+	newSet := map[string]struct{}{}
+	for _, v := range newValues {
+		newSet[v] = struct{}{}
+
+		if _, ok := oldSet[v]; !ok {
+			added = append(added, v)
+		}
+	}
+
+	for _, v := range oldValues {
+		if _, ok := newSet[v]; !ok {
+			removed = append(removed, v)
+		}
+	}
+
+	return added, removed
+}
+
+// matchRenames pairs removed and added fields of the same type by the
+// similarity of their names and descriptions.
+func matchRenames(removed, added []*encoder.Doc) map[*encoder.Doc]*encoder.Doc {
+	type candidate struct {
+		from, to *encoder.Doc
+		score    float64
+	}
+
+	candidates := []candidate{}
+
+	for _, from := range removed {
+		for _, to := range added {
+			if from.Type != to.Type {
+				continue
+			}
+
+			if score := similarity(from, to); score >= renameThreshold {
+				candidates = append(candidates, candidate{from: from, to: to, score: score})
+			}
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].score > candidates[j].score
+	})
+
+	res := map[*encoder.Doc]*encoder.Doc{}
+	used := map[*encoder.Doc]struct{}{}
+
+	for _, c := range candidates {
+		if _, ok := res[c.from]; ok {
+			continue
+		}
+
+		if _, ok := used[c.to]; ok {
+			continue
+		}
+
+		res[c.from] = c.to
+		used[c.to] = struct{}{}
+	}
+
+	return res
+}
+
+func similarity(a, b *encoder.Doc) float64 {
+	if normalizeName(a.Name) == normalizeName(b.Name) {
+		return 1
+	}
+
+	return jaccard(words(a.Description), words(b.Description))
+}
+
+func normalizeName(name string) string {
+	return strings.NewReplacer("-", "", "_", "", ".", "").Replace(strings.ToLower(name))
+}
+
+func words(text string) map[string]struct{} {
+	res := map[string]struct{}{}
+
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	}) {
+		res[w] = struct{}{}
+	}
+
+	return res
+}
+
+func jaccard(a, b map[string]struct{}) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	intersection := 0
+	for w := range a {
+		if _, ok := b[w]; ok {
+			intersection++
+		}
+	}
+
+	return float64(intersection) / float64(len(a)+len(b)-intersection)
+}
+
+func codeList(values []string) string {
+	items := make([]string, 0, len(values))
+	for _, v := range values {
+		items = append(items, "`"+v+"`")
+	}
+
+	return strings.Join(items, ", ")
+}
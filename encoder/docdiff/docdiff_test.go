@@ -0,0 +1,96 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package docdiff
+
+import (
+	"testing"
+
+	"github.com/projectdiscovery/yamldoc-go/encoder"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompare(t *testing.T) {
+	oldDocs := []*encoder.FileDoc{{
+		Structs: []*encoder.Doc{
+			{
+				Type: "Job",
+				Fields: []encoder.Doc{
+					{Name: "name", Type: "string", Description: "Name of the Job"},
+					{Name: "key", Type: "string", Values: []string{"dns", "http", "headless"}},
+					{Name: "bulk_size", Type: "int", Description: "Number of items to process per node"},
+					{Name: "timeout", Type: "int"},
+					{Name: "debug", Type: "bool"},
+				},
+			},
+			{Type: "Legacy"},
+		},
+	}}
+
+	newDocs := []*encoder.FileDoc{{
+		Structs: []*encoder.Doc{
+			{
+				Type: "Job",
+				Fields: []encoder.Doc{
+					{Name: "name", Type: "string", Description: "Name of the Job", Required: true},
+					{Name: "key", Type: "string", Values: []string{"dns", "http", "tcp"}},
+					{Name: "bulk-size", Type: "int", Description: "Number of items to process per node"},
+					{Name: "timeout", Type: "string"},
+					{Name: "debug", Type: "bool", Description: "Deprecated: use verbose instead"},
+					{Name: "verbose", Type: "bool"},
+				},
+			},
+			{Type: "Options"},
+		},
+	}}
+
+	report := Compare(oldDocs, newDocs)
+
+	assert.Equal(t, []Change{
+		{Kind: StructRemoved, Struct: "Legacy", Breaking: true},
+		{Kind: FieldRequired, Struct: "Job", Field: "name", Breaking: true},
+		{Kind: ValuesChanged, Struct: "Job", Field: "key", Added: []string{"tcp"}, Removed: []string{"headless"}, Breaking: true},
+		{Kind: FieldRetyped, Struct: "Job", Field: "timeout", From: "int", To: "string", Breaking: true},
+		{Kind: FieldDeprecated, Struct: "Job", Field: "debug"},
+		{Kind: FieldRenamed, Struct: "Job", Field: "bulk-size", From: "bulk_size", To: "bulk-size", Breaking: true},
+		{Kind: FieldAdded, Struct: "Job", Field: "verbose", To: "bool"},
+		{Kind: StructAdded, Struct: "Options"},
+	}, report.Changes)
+	assert.True(t, report.HasBreaking())
+
+	assert.Equal(t, "## Configuration changes\n"+
+		"\n### Breaking changes\n\n"+
+		"- `Legacy` was removed\n"+
+		"- `Job.name` is now required\n"+
+		"- `Job.key` valid values: added `tcp`, removed `headless`\n"+
+		"- `Job.timeout` type changed from `int` to `string`\n"+
+		"- `Job.bulk_size` was renamed to `bulk-size`\n"+
+		"\n### Deprecations\n\n"+
+		"- `Job.debug` was deprecated\n"+
+		"\n### Other changes\n\n"+
+		"- `Job.verbose` was added with type `bool`\n"+
+		"- `Options` was added\n", report.Markdown())
+
+	data, err := report.JSON()
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"kind": "field_renamed"`)
+}
+
+func TestCompareRenameByDescription(t *testing.T) {
+	oldDocs := []*encoder.FileDoc{{Structs: []*encoder.Doc{{
+		Type:   "Job",
+		Fields: []encoder.Doc{{Name: "workers", Type: "int", Description: "Number of scheduling workers to use"}},
+	}}}}
+	newDocs := []*encoder.FileDoc{{Structs: []*encoder.Doc{{
+		Type:   "Job",
+		Fields: []encoder.Doc{{Name: "scheduling-workers", Type: "int", Description: "Number of scheduling workers to use for ssh"}},
+	}}}}
+
+	report := Compare(oldDocs, newDocs)
+
+	require.Len(t, report.Changes, 1)
+	assert.Equal(t, FieldRenamed, report.Changes[0].Kind)
+	assert.False(t, Compare(oldDocs, oldDocs).HasBreaking())
+}
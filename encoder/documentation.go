@@ -5,6 +5,7 @@
 package encoder
 
 import (
+	"fmt"
 	"reflect"
 	"regexp"
 	"strings"
@@ -42,6 +43,16 @@ type Doc struct {
 	Note string
 	// AppearsIn describes back references for the type.
 	AppearsIn []Appearance
+	// Required marks the field as required in the documentation.
+	Required bool
+	// Default is the default value of the field rendered in the documentation.
+	Default string
+	// Priority orders fields with OrderPriority, higher priority fields come first.
+	Priority int
+	// Sensitive marks fields holding secrets, which are hidden by WithRedaction.
+	Sensitive bool
+	// Style forces the literal, folded or quoted style of string values.
+	Style string
 
 	EnumFields      []string
 	PartDefinitions []KeyValue
@@ -64,6 +75,26 @@ func (d *Doc) AddExample(name string, value interface{}) {
 	})
 }
 
+// DecodeExample decodes YAML example data into the target, failing on
+// fields which are not defined in the target type.
+func DecodeExample(target interface{}, data string) error {
+	decoder := yaml.NewDecoder(strings.NewReader(data))
+	decoder.KnownFields(true)
+
+	return decoder.Decode(target)
+}
+
+// MustDecodeExample decodes YAML example data into the target and returns it.
+// It is used by docgen to load examples from fixture files and panics if
+// the data doesn't match the target type.
+func MustDecodeExample(target interface{}, data string) interface{} {
+	if err := DecodeExample(target, data); err != nil {
+		panic(fmt.Sprintf("invalid example for %T: %s", target, err))
+	}
+
+	return target
+}
+
 // Describe returns a field description.
 func (d *Doc) Describe(field string, short bool) string {
 	desc := ""
@@ -83,42 +114,60 @@ func (d *Doc) Describe(field string, short bool) string {
 
 // Example represents one example snippet for a type.
 type Example struct {
-	populate sync.Once
-	Name     string
+	Name string
 
-	valueMutex sync.RWMutex
-	value      interface{}
+	value interface{}
+
+	populatedMutex sync.RWMutex
+	populated      map[int]interface{}
 }
 
-// Populate populates example value.
+// Populate populates example value for the index.
+//
+// Deprecated: example values are never modified, use Populated to get the populated copy.
 func (e *Example) Populate(index int) {
-	e.populate.Do(func() {
-		if reflect.TypeOf(e.value).Kind() != reflect.Ptr {
-			return
-		}
+	e.Populated(index)
+}
 
-		v := reflect.ValueOf(e.value).Elem()
+// Populated returns a copy of the example value with every documented nested field
+// set to its example at the index, or to the last example if there are fewer.
+// Fields without examples keep the value of the example.
+//
+// Copies are cached per index and must not be modified. The example value itself is never modified.
+func (e *Example) Populated(index int) interface{} {
+	if reflect.TypeOf(e.value) == nil || reflect.TypeOf(e.value).Kind() != reflect.Ptr {
+		return e.value
+	}
 
-		defaultValue := getExample(v, getDoc(e.value), index)
+	e.populatedMutex.RLock()
+	res, ok := e.populated[index]
+	e.populatedMutex.RUnlock()
 
-		e.valueMutex.Lock()
-		defer e.valueMutex.Unlock()
+	if ok {
+		return res
+	}
 
-		if defaultValue != nil {
-			v.Set(defaultValue.Convert(v.Type()))
-		}
+	res = populateExample(e.value, index)
 
-		populateNestedExamples(v, index)
-	})
+	e.populatedMutex.Lock()
+	defer e.populatedMutex.Unlock()
+
+	if e.populated == nil {
+		e.populated = map[int]interface{}{}
+	}
+
+	// keep the first copy if another goroutine populated the same index
+	if populated, ok := e.populated[index]; ok {
+		return populated
+	}
+
+	e.populated[index] = res
+
+	return res
 }
 
 // GetValue returns example value.
 func (e *Example) GetValue() interface{} {
-	e.valueMutex.RLock()
-	defer func() {
-		e.valueMutex.RUnlock()
-	}()
-
 	return e.value
 }
 
@@ -168,6 +217,26 @@ func mergeDoc(a, b *Doc) *Doc {
 		res.Examples = b.Examples
 	}
 
+	if b.Description != "" {
+		res.Description = b.Description
+	}
+
+	if len(b.Values) > 0 {
+		res.Values = b.Values
+	}
+
+	if len(b.EnumFields) > 0 {
+		res.EnumFields = b.EnumFields
+	}
+
+	// priority is a field attribute, never set on types
+	res.Priority = b.Priority
+	res.Sensitive = res.Sensitive || b.Sensitive
+
+	if b.Style != "" {
+		res.Style = b.Style
+	}
+
 	return &res
 }
 
@@ -184,7 +253,44 @@ func getDoc(in interface{}) *Doc {
 	return nil
 }
 
-func addComments(node *yaml.Node, doc *Doc, comments ...int) {
+// commentsDoc returns the doc with comments rendered with the options comments flags.
+func (o *Options) commentsDoc(doc *Doc) *Doc {
+	if doc == nil || !o.Comments.enabled(CommentsDocs) && !o.Comments.enabled(CommentsValues) {
+		return nil
+	}
+
+	res := *doc
+
+	if !o.Comments.enabled(CommentsDocs) {
+		res.Comments = [3]string{}
+	} else if o.Comments.enabled(CommentsFull) && res.Description != "" {
+		if strings.Contains(res.Description, "\n") {
+			res.Comments[HeadComment] = res.Description
+			res.Comments[LineComment] = ""
+		} else {
+			res.Comments[LineComment] = res.Description
+		}
+	}
+
+	values := res.Values
+	if len(values) == 0 {
+		values = res.EnumFields
+	}
+
+	if o.Comments.enabled(CommentsValues) && len(values) > 0 {
+		comment := "one of: " + strings.Join(values, ", ")
+
+		if res.Comments[LineComment] != "" {
+			comment = res.Comments[LineComment] + " # " + comment
+		}
+
+		res.Comments[LineComment] = comment
+	}
+
+	return &res
+}
+
+func addComments(node *yaml.Node, doc *Doc, options *Options, comments ...int) {
 	if doc != nil {
 		dest := []*string{
 			&node.HeadComment,
@@ -203,15 +309,31 @@ func addComments(node *yaml.Node, doc *Doc, comments ...int) {
 		for _, i := range comments {
 			if doc.Comments[i] != "" {
 				*dest[i] = doc.Comments[i]
+
+				if i != LineComment {
+					*dest[i] = foldComment(*dest[i], options.LineWidth)
+				}
 			}
 		}
 	}
 }
 
+var (
+	lineStartRe    = regexp.MustCompile(`(?m)^(.)`)
+	commentStartRe = regexp.MustCompile(`(?m)^#`)
+)
+
+// renderExample renders the examples of the field at the path, examples which can't
+// be rendered are reported with the options and skipped.
+//
 //nolint:gocyclo
-func renderExample(key string, doc *Doc, flags CommentsFlags) string {
+func renderExample(key string, path []string, doc *Doc, options *Options) (string, error) {
 	if doc == nil {
-		return ""
+		return "", nil
+	}
+
+	if options.redacts(doc) && options.Redaction == RedactOmit {
+		return "", nil
 	}
 
 	examples := []string{}
@@ -223,30 +345,37 @@ func renderExample(key string, doc *Doc, flags CommentsFlags) string {
 			continue
 		}
 
-		if v.Kind() != reflect.Ptr {
-			v = reflect.Indirect(v)
-		}
+		node, err := toYamlNodeAt(e.Populated(i), path, options)
+		if err != nil {
+			if err = exampleError(path, i, e, err, options); err != nil {
+				return "", err
+			}
 
-		defaultValue := v.Interface()
+			continue
+		}
 
-		e.Populate(i)
+		quoteScalar(node, options)
+		applyStyle(node, doc.Style)
 
-		node, err := toYamlNode(defaultValue, flags)
-		if err != nil {
-			continue
+		if options.redacts(doc) {
+			redactNode(node, options)
 		}
 
 		if key != "" {
 			node, err = toYamlNode(map[string]*yaml.Node{
 				key: node,
-			}, flags)
+			}, options)
 			if err != nil {
+				if err = exampleError(path, i, e, err, options); err != nil {
+					return "", err
+				}
+
 				continue
 			}
 		}
 
-		if i == 0 && flags.enabled(CommentsDocs) {
-			addComments(node, doc, HeadComment, LineComment)
+		if i == 0 {
+			addComments(node, options.commentsDoc(doc), options, HeadComment, LineComment)
 		}
 
 		// replace head comment with line comment
@@ -263,25 +392,44 @@ func renderExample(key string, doc *Doc, flags CommentsFlags) string {
 			node.HeadComment = node.HeadComment + e.Name + "\n"
 		}
 
-		data, err := yaml.Marshal(node)
+		data, err := marshalYaml(node, options)
 		if err != nil {
+			if err = exampleError(path, i, e, err, options); err != nil {
+				return "", err
+			}
+
 			continue
 		}
 
 		if key == "" {
 			// re-indent
-			data = regexp.MustCompile(`(?m)^(.)`).ReplaceAll(data, []byte("  $1"))
+			data = lineStartRe.ReplaceAll(data, []byte(options.exampleIndent()+"$1"))
 		} else {
 			// don't collapse comment
-			data = regexp.MustCompile(`(?m)^#`).ReplaceAll(data, []byte("# #"))
+			data = commentStartRe.ReplaceAll(data, []byte("# #"))
 		}
 
 		examples = append(examples, string(data))
 	}
 
-	return strings.Join(examples, "")
+	return strings.Join(examples, ""), nil
 }
 
+// populateExample returns a populated copy of the example value pointer.
+func populateExample(value interface{}, index int) interface{} {
+	res := deepCopy(reflect.ValueOf(value))
+	v := res.Elem()
+
+	if defaultValue := getExample(v, getDoc(value), index); defaultValue != nil {
+		v.Set(defaultValue.Convert(v.Type()))
+	}
+
+	populateNestedExamples(v, index, map[reflect.Type]bool{})
+
+	return res.Interface()
+}
+
+// getExample returns a copy of the documented example value at the index.
 func getExample(v reflect.Value, doc *Doc, index int) *reflect.Value {
 	if doc == nil || len(doc.Examples) == 0 {
 		return nil
@@ -292,7 +440,7 @@ func getExample(v reflect.Value, doc *Doc, index int) *reflect.Value {
 		index = numExamples - 1
 	}
 
-	defaultValue := reflect.ValueOf(doc.Examples[index].GetValue())
+	defaultValue := deepCopy(reflect.ValueOf(doc.Examples[index].GetValue()))
 	if !isEmpty(defaultValue) {
 		if v.Kind() != reflect.Ptr && defaultValue.Kind() == reflect.Ptr {
 			defaultValue = defaultValue.Elem()
@@ -302,11 +450,26 @@ func getExample(v reflect.Value, doc *Doc, index int) *reflect.Value {
 	return &defaultValue
 }
 
+// populateNestedExamples sets the documented fields of the nested structs to
+// their examples. Struct types already being populated up the value are
+// skipped, so self-referential types and cyclic values terminate.
+//
 //nolint:gocyclo
-func populateNestedExamples(v reflect.Value, index int) {
+func populateNestedExamples(v reflect.Value, index int, populating map[reflect.Type]bool) {
 	//nolint:exhaustive
 	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			populateNestedExamples(v.Elem(), index, populating)
+		}
 	case reflect.Struct:
+		if populating[v.Type()] {
+			return
+		}
+
+		populating[v.Type()] = true
+		defer delete(populating, v.Type())
+
 		doc := getDoc(v.Interface())
 
 		for i := 0; i < v.NumField(); i++ {
@@ -323,15 +486,15 @@ func populateNestedExamples(v reflect.Value, index int) {
 				}
 			}
 
-			populateNestedExamples(field, index)
+			populateNestedExamples(field, index, populating)
 		}
 	case reflect.Map:
 		for _, key := range v.MapKeys() {
-			populateNestedExamples(v.MapIndex(key), index)
+			populateNestedExamples(v.MapIndex(key), index, populating)
 		}
 	case reflect.Slice:
 		for i := 0; i < v.Len(); i++ {
-			populateNestedExamples(v.Index(i), index)
+			populateNestedExamples(v.Index(i), index, populating)
 		}
 	}
 }
@@ -0,0 +1,102 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package encoder
+
+import (
+	"reflect"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// elementDoc returns the documentation of the slice, array or map element type.
+func elementDoc(t reflect.Type) *Doc {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	//nolint:exhaustive
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+	default:
+		return nil
+	}
+
+	elem := t.Elem()
+	for elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+
+	// pointer has both value and pointer receiver methods
+	return getDoc(reflect.New(elem).Interface())
+}
+
+// mapExampleKey is the placeholder key of map value examples.
+const mapExampleKey = "<key>"
+
+// withElementExamples returns the doc of a slice or map field with the examples of the
+// element type if the field has no examples of its own. Map values are rendered
+// under a placeholder key.
+func withElementExamples(doc *Doc, t reflect.Type) *Doc {
+	if doc != nil && len(doc.Examples) > 0 {
+		return doc
+	}
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	//nolint:exhaustive
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+	default:
+		return doc
+	}
+
+	elem := elementDoc(t)
+	if elem == nil || len(elem.Examples) == 0 {
+		return doc
+	}
+
+	var res Doc
+	if doc != nil {
+		res = *doc
+	}
+
+	for i, e := range elem.Examples {
+		if t.Kind() == reflect.Map {
+			res.AddExample(e.GetName(), map[interface{}]interface{}{mapExampleKeyOf(t.Key()): e.Populated(i)})
+		} else {
+			res.AddExample(e.GetName(), []interface{}{e.Populated(i)})
+		}
+	}
+
+	return &res
+}
+
+// mapExampleKeyOf returns the placeholder key for string keys and the zero value of other key types.
+func mapExampleKeyOf(t reflect.Type) interface{} {
+	if t.Kind() == reflect.String {
+		return mapExampleKey
+	}
+
+	return reflect.Zero(t).Interface()
+}
+
+// addItemComments documents a sequence item with the element type docs.
+func addItemComments(node *yaml.Node, doc *Doc, options *Options) {
+	if doc = options.commentsDoc(doc); doc == nil {
+		return
+	}
+
+	addComments(node, doc, options, HeadComment, LineComment)
+
+	if node.Kind != yaml.ScalarNode {
+		if node.HeadComment == "" {
+			node.HeadComment = foldComment(node.LineComment, options.LineWidth)
+		}
+
+		node.LineComment = ""
+	}
+}
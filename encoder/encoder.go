@@ -5,9 +5,10 @@
 package encoder
 
 import (
+	"encoding"
 	"reflect"
-	"sort"
 	"strings"
+	"time"
 
 	yaml "gopkg.in/yaml.v3"
 )
@@ -28,13 +29,13 @@ func NewEncoder(value interface{}, opts ...Option) *Encoder {
 
 // Marshal converts value to YAML-serializable value (suitable for MarshalYAML).
 func (e *Encoder) Marshal() (*yaml.Node, error) {
-	node, err := toYamlNode(e.value, e.options.Comments)
+	node, err := toYamlNode(e.value, e.options)
 	if err != nil {
 		return nil, err
 	}
 
-	if e.options.Comments.enabled(CommentsDocs) {
-		addComments(node, getDoc(e.value), HeadComment, LineComment)
+	if doc := e.options.commentsDoc(getDoc(e.value)); doc != nil {
+		addComments(node, doc, e.options, HeadComment, LineComment)
 	}
 
 	return node, nil
@@ -43,10 +44,6 @@ func (e *Encoder) Marshal() (*yaml.Node, error) {
 // Encode converts value to yaml.
 //nolint:gocyclo
 func (e *Encoder) Encode() ([]byte, error) {
-	if e.options.Comments == CommentsDisabled {
-		return yaml.Marshal(e.value)
-	}
-
 	node, err := e.Marshal()
 	if err != nil {
 		return nil, err
@@ -69,22 +66,50 @@ func (e *Encoder) Encode() ([]byte, error) {
 		}
 		return []byte(strings.Join(lines, "\n")), nil
 	}
-	return yaml.Marshal(node)
+	return marshalYaml(node, e.options)
 }
 
+// isEmpty checks if the value is omitted with omitempty, the same way as yaml.v3 does.
+//
+//nolint:gocyclo
 func isEmpty(value reflect.Value) bool {
 	if !value.IsValid() {
 		return true
 	}
 
+	kind := value.Kind()
+
+	if z, ok := value.Interface().(yaml.IsZeroer); ok {
+		if (kind == reflect.Ptr || kind == reflect.Interface) && value.IsNil() {
+			return true
+		}
+
+		return z.IsZero()
+	}
+
 	//nolint:exhaustive
-	switch value.Kind() {
-	case reflect.Ptr:
+	switch kind {
+	case reflect.Ptr, reflect.Interface:
 		return value.IsNil()
-	case reflect.Map:
-		return len(value.MapKeys()) == 0
-	case reflect.Slice:
+	case reflect.Map, reflect.Slice, reflect.String:
 		return value.Len() == 0
+	case reflect.Struct:
+		t := value.Type()
+
+		for i := 0; i < value.NumField(); i++ {
+			// unexported fields are not encoded
+			if t.Field(i).PkgPath != "" {
+				continue
+			}
+
+			if !isEmpty(value.Field(i)) {
+				return false
+			}
+		}
+
+		return true
+	case reflect.Array:
+		return false
 	default:
 		return value.IsZero()
 	}
@@ -104,37 +129,82 @@ func isNil(value reflect.Value) bool {
 	}
 }
 
+// encodeState holds the state of a single value encoding.
+type encodeState struct {
+	options *Options
+	// origins maps nodes to the pointers they were encoded from
+	origins map[*yaml.Node]pointerKey
+	// path is the path of the encoded value, keys and sequence indexes
+	path []string
+	// depth is the number of keys in the path, sequence indexes excluded
+	depth int
+}
+
+func toYamlNode(in interface{}, options *Options) (*yaml.Node, error) {
+	return toYamlNodeAt(in, nil, options)
+}
+
+// toYamlNodeAt encodes the value found at the path.
+func toYamlNodeAt(in interface{}, path []string, options *Options) (*yaml.Node, error) {
+	s := &encodeState{
+		options: options,
+		origins: map[*yaml.Node]pointerKey{},
+		path:    path,
+	}
+
+	node, err := s.encode(in)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.Anchors != AnchorsDisabled {
+		s.addAnchors(node)
+	}
+
+	return node, nil
+}
+
 //nolint:gocyclo,cyclop
-func toYamlNode(in interface{}, flags CommentsFlags) (*yaml.Node, error) {
+func (s *encodeState) encode(in interface{}) (*yaml.Node, error) {
 	node := &yaml.Node{}
+	options := s.options
 
-	// do not wrap yaml.Node into yaml.Node
-	if n, ok := in.(*yaml.Node); ok {
-		return n, nil
+	if v := reflect.ValueOf(in); !v.IsValid() || v.Kind() == reflect.Ptr && v.IsNil() {
+		return node, node.Encode(nil)
 	}
 
-	// if input implements yaml.Marshaler we should use that marshaller instead
-	// same way as regular yaml marshal does
-	if m, ok := in.(yaml.Marshaler); ok {
-		res, err := m.MarshalYAML()
+	// types with custom encoding are handled in the same order as regular yaml marshal does
+	switch value := in.(type) {
+	case *yaml.Node:
+		// do not wrap yaml.Node into yaml.Node
+		return value, nil
+	case yaml.Node:
+		return &value, nil
+	case time.Time, *time.Time:
+		return node, s.encodeError(in, node.Encode(in))
+	case yaml.Marshaler:
+		res, err := value.MarshalYAML()
 		if err != nil {
-			return nil, err
+			return nil, s.encodeError(in, err)
 		}
 
-		if n, ok := res.(*yaml.Node); ok {
-			return n, nil
+		return s.encode(res)
+	case encoding.TextMarshaler:
+		text, err := value.MarshalText()
+		if err != nil {
+			return nil, s.encodeError(in, err)
 		}
 
-		in = res
+		return node, s.encodeError(in, node.Encode(string(text)))
 	}
 
 	v := reflect.ValueOf(in)
 	if v.Kind() == reflect.Ptr {
+		s.trackPointer(node, v)
+
 		v = v.Elem()
 	}
 
-	doc := getDoc(in)
-
 	//nolint:exhaustive
 	switch v.Kind() {
 	case reflect.Struct:
@@ -143,79 +213,57 @@ func toYamlNode(in interface{}, flags CommentsFlags) (*yaml.Node, error) {
 		t := v.Type()
 
 		examples := []string{}
+		priorities := map[*yaml.Node]int{}
 
-		for i := 0; i < v.NumField(); i++ {
-			// skip unexported fields
-			if !v.Field(i).CanInterface() {
-				continue
-			}
-
-			tag := t.Field(i).Tag.Get("yaml")
-			parts := strings.Split(tag, ",")
-			fieldName := parts[0]
-			parts = parts[1:]
+		plan := getStructPlan(reflect.TypeOf(in), t, options)
+		if plan.err != nil {
+			return nil, s.encodeError(in, plan.err)
+		}
 
-			tag = t.Field(i).Tag.Get("talos")
-			if tag != "" {
-				parts = append(parts, strings.Split(tag, ",")...)
-			}
+		structDoc := getDoc(in)
 
-			if fieldName == "" {
-				fieldName = strings.ToLower(t.Field(i).Name)
-			}
+		for i := range plan.fields {
+			field := &plan.fields[i]
+			tag := &field.tag
+			fieldName := tag.name
 
-			if fieldName == "-" {
-				continue
-			}
+			fieldOptions := s.optionsAt(s.childPath(fieldName), s.depth+1)
 
 			var (
-				empty = isEmpty(v.Field(i))
-				null  = isNil(v.Field(i))
-
-				skip   bool
-				inline bool
-				flow   bool
+				empty = isEmpty(v.Field(field.index))
+				null  = isNil(v.Field(field.index))
+				skip  = tag.omitEmpty && empty
 			)
 
-			for _, part := range parts {
-				if part == "omitempty" && empty {
-					skip = true
-				}
-
-				if part == "omitonlyifnil" && !null {
-					skip = false
-				}
-
-				if part == "inline" {
-					inline = true
-				}
-
-				if part == "flow" {
-					flow = true
-				}
+			if tag.omitOnlyIfNil && !null {
+				skip = false
 			}
 
-			var value interface{}
-			if v.Field(i).CanInterface() {
-				value = v.Field(i).Interface()
+			if tag.omitAlways {
+				skip, empty = true, true
 			}
 
-			// get documentation data either from field, or from type
-			var fieldDoc *Doc
+			value := v.Field(field.index).Interface()
+			fieldDoc, exampleDoc := field.docs(structDoc, v.Field(field.index))
 
-			if doc != nil {
-				fieldDoc = mergeDoc(getDoc(value), doc.Field(i))
-			} else {
-				fieldDoc = getDoc(value)
+			if options.redacts(fieldDoc) && options.Redaction == RedactOmit {
+				continue
+			}
+
+			if empty && fieldOptions.Comments.enabled(CommentsExamples) {
+				fieldDoc = exampleDoc
 			}
 
 			// inlineExample is rendered after the value
 			var inlineExample string
 
-			if empty && flags.enabled(CommentsExamples) && fieldDoc != nil {
+			if empty && fieldOptions.Comments.enabled(CommentsExamples) && fieldDoc != nil {
 				if skip {
 					// render example to be appended to the end of the rendered struct
-					example := renderExample(fieldName, fieldDoc, flags)
+					example, err := renderExample(fieldName, s.childPath(fieldName), fieldDoc, fieldOptions)
+					if err != nil {
+						return nil, err
+					}
 
 					if example != "" {
 						examples = append(examples, example)
@@ -225,7 +273,12 @@ func toYamlNode(in interface{}, flags CommentsFlags) (*yaml.Node, error) {
 					fieldDocCopy := *fieldDoc
 					fieldDocCopy.Comments = [3]string{}
 
-					inlineExample = renderExample("", &fieldDocCopy, flags)
+					var err error
+
+					inlineExample, err = renderExample("", s.childPath(fieldName), &fieldDocCopy, fieldOptions)
+					if err != nil {
+						return nil, err
+					}
 				}
 			}
 
@@ -234,21 +287,39 @@ func toYamlNode(in interface{}, flags CommentsFlags) (*yaml.Node, error) {
 			}
 
 			var style yaml.Style
-			if flow {
+			if tag.flow {
 				style |= yaml.FlowStyle
 			}
 
-			if inline {
-				child, err := toYamlNode(value, flags)
+			if tag.inline {
+				child, err := s.encode(value)
 				if err != nil {
 					return nil, err
 				}
 
+				if options.redacts(fieldDoc) {
+					s.redact(child)
+				}
+
 				if child.Kind == yaml.MappingNode || child.Kind == yaml.SequenceNode {
 					appendNodes(node, child.Content...)
 				}
-			} else if err := addToMap(node, fieldDoc, fieldName, value, style, flags); err != nil {
-				return nil, err
+			} else {
+				if err := s.addToMap(node, fieldDoc, fieldName, value, style); err != nil {
+					return nil, err
+				}
+
+				if fieldDoc != nil {
+					applyStyle(node.Content[len(node.Content)-1], fieldDoc.Style)
+				}
+
+				if options.redacts(fieldDoc) {
+					s.redact(node.Content[len(node.Content)-1])
+				}
+
+				if fieldDoc != nil {
+					priorities[node.Content[len(node.Content)-2]] = fieldDoc.Priority
+				}
 			}
 
 			if inlineExample != "" {
@@ -262,6 +333,8 @@ func toYamlNode(in interface{}, flags CommentsFlags) (*yaml.Node, error) {
 			}
 		}
 
+		sortFields(node, priorities, options)
+
 		if len(examples) > 0 {
 			comment := strings.Join(examples, "\n")
 			// add rendered example to the foot comment of the last node
@@ -275,37 +348,52 @@ func toYamlNode(in interface{}, flags CommentsFlags) (*yaml.Node, error) {
 	case reflect.Map:
 		node.Kind = yaml.MappingNode
 		keys := v.MapKeys()
-		// always interate keys in alphabetical order to preserve the same output for maps
-		sort.Slice(keys, func(i, j int) bool {
-			return keys[i].String() < keys[j].String()
-		})
+		// always iterate keys in sorted order to preserve the same output for maps
+		sortMapKeys(keys, options)
+
+		// values are documented by their type the same way as struct fields
+		elemDoc := cachedElementDoc(v.Type())
 
 		for _, k := range keys {
 			element := v.MapIndex(k)
 			value := element.Interface()
 
-			if err := addToMap(node, nil, k.Interface(), value, 0, flags); err != nil {
+			if err := s.addToMap(node, elemDoc, k.Interface(), value, 0); err != nil {
 				return nil, err
 			}
 		}
-	case reflect.Slice:
+	case reflect.Slice, reflect.Array:
 		node.Kind = yaml.SequenceNode
 		nodes := make([]*yaml.Node, v.Len())
 
+		path := s.path
+		elemDoc := cachedElementDoc(v.Type())
+
 		for i := 0; i < v.Len(); i++ {
 			element := v.Index(i)
 
 			var err error
 
-			nodes[i], err = toYamlNode(element.Interface(), flags)
+			s.path = s.childPath(i)
+			itemOptions := s.optionsAt(s.path, s.depth)
+			nodes[i], err = s.encode(element.Interface())
+			s.path = path
+
 			if err != nil {
 				return nil, err
 			}
+
+			quoteScalar(nodes[i], options)
+			addItemComments(nodes[i], elemDoc, itemOptions)
 		}
 		appendNodes(node, nodes...)
 	default:
+		if encodeScalar(node, v) {
+			return node, nil
+		}
+
 		if err := node.Encode(in); err != nil {
-			return nil, err
+			return nil, s.encodeError(in, err)
 		}
 	}
 
@@ -320,28 +408,36 @@ func appendNodes(dest *yaml.Node, nodes ...*yaml.Node) {
 	dest.Content = append(dest.Content, nodes...)
 }
 
-func addToMap(dest *yaml.Node, doc *Doc, fieldName, in interface{}, style yaml.Style, flags CommentsFlags) error {
-	key, err := toYamlNode(fieldName, flags)
+func (s *encodeState) addToMap(dest *yaml.Node, doc *Doc, fieldName, in interface{}, style yaml.Style) error {
+	key, err := s.encode(fieldName)
 	if err != nil {
 		return err
 	}
 
-	value, err := toYamlNode(in, flags)
+	path, depth := s.path, s.depth
+	s.path, s.depth = s.childPath(fieldName), depth+1
+	options := s.optionsAt(s.path, s.depth)
+
+	value, err := s.encode(in)
+
+	s.path, s.depth = path, depth
+
 	if err != nil {
 		return err
 	}
 
 	value.Style = style
+	quoteScalar(value, options)
 
-	if flags.enabled(CommentsDocs) {
-		addComments(key, doc, HeadComment, FootComment)
-		addComments(value, doc, LineComment)
+	if doc := options.commentsDoc(doc); doc != nil {
+		addComments(key, doc, options, HeadComment, FootComment)
+		addComments(value, doc, options, LineComment)
 	}
 
 	// override head comment with line comment for non-scalar nodes
 	if value.Kind != yaml.ScalarNode {
 		if key.HeadComment == "" {
-			key.HeadComment = value.LineComment
+			key.HeadComment = foldComment(value.LineComment, options.LineWidth)
 		}
 
 		value.LineComment = ""
@@ -178,6 +178,7 @@ slice: []
 # complex slice
 complex_slice: []
 #   # slice example
+#   # endpoint settings
 #   - host: 127.0.0.1 # endpoint host
 #     port: 5554 # custom port
 
@@ -286,6 +287,7 @@ slice: []
 # complex slice
 complex_slice: []
 map:
+    # endpoint settings
     endpoint:
         host: "" # endpoint host
 # some text example for map
@@ -307,7 +309,7 @@ slice: []
 # complex slice
 complex_slice: []
 map:
-    endpoint: null
+    endpoint: null # endpoint settings
 # some text example for map
 `,
 			options: []Option{
@@ -327,9 +329,11 @@ map:
 slice: []
 # complex slice
 complex_slice:
+    # endpoint settings
     - host: "" # endpoint host
       port: 8080 # custom port
 map:
+    # endpoint settings
     endpoint:
         host: "" # endpoint host
 # some text example for map
@@ -479,6 +483,7 @@ slice: []
 # complex slice
 complex_slice: []
 #   # slice example
+#   # endpoint settings
 #   - host: 127.0.0.1 # endpoint host
 #     port: 5554 # custom port
 
@@ -544,6 +549,414 @@ func (suite *EncoderSuite) TestConcurrent() {
 	wg.Wait()
 }
 
+func (suite *EncoderSuite) TestDecodeExample() {
+	value := MustDecodeExample(new(Endpoint), "host: 127.0.0.1\nport: 5554\n")
+	suite.Assert().Equal(&Endpoint{Host: "127.0.0.1", Port: 5554}, value)
+
+	err := DecodeExample(new(Endpoint), "host: 127.0.0.1\nunknown: true\n")
+	suite.Assert().Error(err)
+
+	suite.Assert().Panics(func() {
+		MustDecodeExample(new([]string), "key: value\n")
+	})
+}
+
+type Ordered struct {
+	Zeta  string                 `yaml:"zeta"`
+	Alpha string                 `yaml:"alpha"`
+	Mid   string                 `yaml:"mid"`
+	Ints  map[int]string         `yaml:"ints"`
+	Mixed map[interface{}]string `yaml:"mixed"`
+}
+
+var orderedDoc = &Doc{
+	Fields: []Doc{
+		{Name: "zeta"},
+		{Name: "alpha"},
+		{Name: "mid", Priority: 10},
+		{Name: "ints", Priority: -1},
+		{Name: "mixed", Priority: -1},
+	},
+}
+
+func (o Ordered) Doc() *Doc {
+	return orderedDoc
+}
+
+func (suite *EncoderSuite) TestOrdering() {
+	value := &Ordered{
+		Ints:  map[int]string{10: "ten", 2: "two", -1: "minus one"},
+		Mixed: map[interface{}]string{"b": "string", 3: "int", true: "true", false: "false", 1: "one"},
+	}
+
+	maps := `ints:
+    -1: minus one
+    2: two
+    10: ten
+mixed:
+    false: "false"
+    true: "true"
+    1: one
+    3: int
+    b: string
+`
+
+	for _, test := range []struct {
+		name     string
+		options  []Option
+		expected string
+	}{
+		{
+			name:     "declaration",
+			expected: "zeta: \"\"\nalpha: \"\"\nmid: \"\"\n" + maps,
+		},
+		{
+			name:    "alphabetical",
+			options: []Option{WithOrdering(OrderAlphabetical)},
+			expected: `alpha: ""
+ints:
+    -1: minus one
+    2: two
+    10: ten
+mid: ""
+mixed:
+    false: "false"
+    true: "true"
+    1: one
+    3: int
+    b: string
+zeta: ""
+`,
+		},
+		{
+			name:     "priority",
+			options:  []Option{WithOrdering(OrderPriority)},
+			expected: "mid: \"\"\nzeta: \"\"\nalpha: \"\"\n" + maps,
+		},
+		{
+			name: "custom",
+			options: []Option{WithKeyComparator(func(a, b string) bool {
+				return a > b
+			})},
+			expected: `zeta: ""
+mixed:
+    true: "true"
+    false: "false"
+    b: string
+    3: int
+    1: one
+mid: ""
+ints:
+    2: two
+    10: ten
+    -1: minus one
+alpha: ""
+`,
+		},
+	} {
+		data, err := NewEncoder(value, append(test.options, WithComments(CommentsDocs))...).Encode()
+		suite.Require().NoError(err)
+		suite.Assert().Equal(test.expected, string(data), test.name)
+	}
+}
+
+func (suite *EncoderSuite) TestFormatting() {
+	value := &Config{
+		Slice:        []string{"a", "multi\nline"},
+		ComplexSlice: []*Endpoint{{Host: "example.com", Port: 443}},
+	}
+
+	data, err := NewEncoder(value,
+		WithIndent(2),
+		WithCompactSequences(),
+		WithQuoteStyle(QuoteDouble),
+		WithComments(CommentsDocs),
+	).Encode()
+	suite.Require().NoError(err)
+	suite.Assert().Equal(`integer: 0
+# <<<
+slice:
+- "a"
+- |-
+  multi
+  line
+# complex slice
+complex_slice:
+# endpoint settings
+- host: "example.com" # endpoint host
+  port: 443 # custom port
+map: {}
+# some text example for map
+`, string(data))
+
+	data, err = NewEncoder(&Config{}, WithIndent(2), WithComments(CommentsExamples)).Encode()
+	suite.Require().NoError(err)
+	suite.Assert().Contains(string(data), "# nilslice:\n#   - name: foo\n")
+
+	data, err = NewEncoder(value, WithCompactSequences(), WithComments(CommentsDisabled)).Encode()
+	suite.Require().NoError(err)
+	suite.Assert().Contains(string(data), "slice:\n  - a\n  - |-\n    multi\n    line\n")
+
+	// block scalars are kept intact
+	data, err = NewEncoder(&Config{Slice: []string{"key:\n    - item"}}, WithCompactSequences()).Encode()
+	suite.Require().NoError(err)
+	suite.Assert().Contains(string(data), "slice:\n  - |-\n    key:\n        - item\n")
+}
+
+func (suite *EncoderSuite) TestLineWidth() {
+	doc := &Doc{}
+	doc.Comments[HeadComment] = "This is a long description which does not fit into a single line of the configured width."
+
+	node := &yaml.Node{}
+	addComments(node, doc, newOptions(WithLineWidth(40)), HeadComment)
+
+	suite.Assert().Equal("This is a long description which does\nnot fit into a single line of the\nconfigured width.", node.HeadComment)
+}
+
+type Scripts struct {
+	Run     string            `yaml:"run"`
+	Cleanup string            `yaml:"cleanup"`
+	Env     map[string]string `yaml:"env"`
+	Notes   string            `yaml:"notes"`
+}
+
+var scriptsDoc = &Doc{
+	Fields: []Doc{
+		{Name: "run", Comments: [3]string{"", "Script to run."}},
+		{Name: "cleanup", Style: StyleFolded},
+		{Name: "env"},
+		{Name: "notes", Style: StyleQuoted},
+	},
+}
+
+func init() {
+	scriptsDoc.Fields[0].AddExample("", "set -e\nmake\n")
+}
+
+func (s Scripts) Doc() *Doc {
+	return scriptsDoc
+}
+
+func (suite *EncoderSuite) TestMultiline() {
+	value := &Scripts{
+		Run:     "set -e\nmake test\n",
+		Cleanup: "rm -rf build",
+		Env:     map[string]string{"CERT": "line 1\nline 2"},
+		Notes:   "a\nb",
+	}
+
+	data, err := NewEncoder(value, WithQuoteStyle(QuoteDouble), WithComments(CommentsDisabled)).Encode()
+	suite.Require().NoError(err)
+	suite.Assert().Equal(`run: |
+    set -e
+    make test
+cleanup: >-
+    rm -rf build
+env:
+    CERT: |-
+        line 1
+        line 2
+notes: "a\nb"
+`, string(data))
+
+	data, err = NewEncoder(value, WithMultilineStyle(MultilineQuoted), WithComments(CommentsDisabled)).Encode()
+	suite.Require().NoError(err)
+	suite.Assert().Contains(string(data), `run: "set -e\nmake test\n"`)
+	suite.Assert().Contains(string(data), `CERT: "line 1\nline 2"`)
+
+	decoded := &Scripts{}
+	suite.Require().NoError(yaml.Unmarshal(data, decoded))
+	suite.Assert().Equal(value, decoded)
+
+	// examples use the same style
+	data, err = NewEncoder(&Scripts{}, WithMultilineStyle(MultilineFolded)).Encode()
+	suite.Require().NoError(err)
+	suite.Assert().Contains(string(data), "run: \"\" # Script to run.\n#   >\n#       set -e\n")
+}
+
+type Scan struct {
+	Protocol string   `yaml:"protocol"`
+	Targets  []string `yaml:"targets"`
+	Mode     string   `yaml:"mode"`
+}
+
+var scanDoc = &Doc{
+	Fields: []Doc{
+		{
+			Name:        "protocol",
+			Comments:    [3]string{"", "Protocol to scan with."},
+			Description: "Protocol to scan with.\nHeadless requires a browser.",
+			Values:      []string{"dns", "http", "headless"},
+		},
+		{
+			Name:        "targets",
+			Comments:    [3]string{"", "Targets to scan."},
+			Description: "Targets to scan.",
+		},
+		{
+			Name:       "mode",
+			EnumFields: []string{"fast", "full"},
+		},
+	},
+}
+
+func (s Scan) Doc() *Doc {
+	return scanDoc
+}
+
+func (suite *EncoderSuite) TestCommentsValues() {
+	value := &Scan{Protocol: "dns", Targets: []string{"example.com"}, Mode: "fast"}
+
+	for _, test := range []struct {
+		name     string
+		flags    CommentsFlags
+		expected string
+	}{
+		{
+			name:  "docs",
+			flags: CommentsDocs,
+			expected: `protocol: dns # Protocol to scan with.
+# Targets to scan.
+targets:
+    - example.com
+mode: fast
+`,
+		},
+		{
+			name:  "values",
+			flags: CommentsDocs | CommentsValues,
+			expected: `protocol: dns # Protocol to scan with. # one of: dns, http, headless
+# Targets to scan.
+targets:
+    - example.com
+mode: fast # one of: fast, full
+`,
+		},
+		{
+			name:  "values only",
+			flags: CommentsValues,
+			expected: `protocol: dns # one of: dns, http, headless
+targets:
+    - example.com
+mode: fast # one of: fast, full
+`,
+		},
+		{
+			name:  "full",
+			flags: CommentsDocs | CommentsFull | CommentsValues,
+			expected: `# Protocol to scan with.
+# Headless requires a browser.
+protocol: dns # one of: dns, http, headless
+# Targets to scan.
+targets:
+    - example.com
+mode: fast # one of: fast, full
+`,
+		},
+	} {
+		data, err := NewEncoder(value, WithComments(test.flags)).Encode()
+		suite.Require().NoError(err)
+		suite.Assert().Equal(test.expected, string(data), test.name)
+	}
+}
+
+type Protocol string
+
+var protocolDoc = &Doc{
+	Comments:    [3]string{"", "Protocol to scan with."},
+	Description: "Protocol to scan with.\nHeadless requires a browser.",
+	Values:      []string{"dns", "http", "headless"},
+}
+
+func (p Protocol) Doc() *Doc {
+	return protocolDoc
+}
+
+func (suite *EncoderSuite) TestCommentsRoot() {
+	for _, test := range []struct {
+		name     string
+		flags    CommentsFlags
+		expected string
+	}{
+		{
+			name:     "docs",
+			flags:    CommentsDocs,
+			expected: "dns # Protocol to scan with.\n",
+		},
+		{
+			name:     "values only",
+			flags:    CommentsValues,
+			expected: "dns # one of: dns, http, headless\n",
+		},
+		{
+			name:     "full",
+			flags:    CommentsDocs | CommentsFull | CommentsValues,
+			expected: "# Protocol to scan with.\n# Headless requires a browser.\ndns # one of: dns, http, headless\n",
+		},
+		{
+			name:     "disabled",
+			flags:    CommentsDisabled,
+			expected: "dns\n",
+		},
+	} {
+		data, err := NewEncoder(Protocol("dns"), WithComments(test.flags)).Encode()
+		suite.Require().NoError(err)
+		suite.Assert().Equal(test.expected, string(data), test.name)
+	}
+}
+
+type Step struct {
+	Run string `yaml:"run"`
+}
+
+type Pipeline struct {
+	Steps  []*Step         `yaml:"steps"`
+	Stages map[string]Step `yaml:"stages"`
+}
+
+var stepDoc = &Doc{
+	Comments: [3]string{"", "Step of the pipeline."},
+	Fields: []Doc{
+		{Name: "run", Comments: [3]string{"", "Command to run."}},
+	},
+}
+
+func init() {
+	stepDoc.AddExample("Build step", &Step{Run: "make"})
+}
+
+func (s Step) Doc() *Doc {
+	return stepDoc
+}
+
+func (suite *EncoderSuite) TestElementDocs() {
+	data, err := NewEncoder(&Pipeline{}).Encode()
+	suite.Require().NoError(err)
+	suite.Assert().Equal(`steps: []
+#   # Build step
+#   - run: make # Command to run.
+
+stages: {}
+#   # Build step
+#   <key>:
+#       run: make # Command to run.
+`, string(data))
+
+	data, err = NewEncoder(&Pipeline{
+		Steps:  []*Step{{Run: "make"}},
+		Stages: map[string]Step{"test": {Run: "make test"}},
+	}, WithComments(CommentsDocs)).Encode()
+	suite.Require().NoError(err)
+	suite.Assert().Equal(`steps:
+    # Step of the pipeline.
+    - run: make # Command to run.
+stages:
+    # Step of the pipeline.
+    test:
+        run: make test # Command to run.
+`, string(data))
+}
+
 func decodeToMap(data []byte) (map[interface{}]interface{}, error) {
 	raw := map[interface{}]interface{}{}
 	err := yaml.Unmarshal(data, &raw)
@@ -551,6 +964,74 @@ func decodeToMap(data []byte) (map[interface{}]interface{}, error) {
 	return raw, err
 }
 
+func benchmarkConfig() *Config {
+	endpoints := make([]*Endpoint, 0, 50)
+	hosts := make(map[string]*Endpoint, 50)
+
+	for i := 0; i < 50; i++ {
+		endpoint := &Endpoint{Host: "127.0.0.1", Port: 8000 + i}
+		endpoints = append(endpoints, endpoint)
+		hosts[endpoint.Host+string(rune('a'+i%26))] = endpoint
+	}
+
+	return &Config{
+		Integer:      1,
+		Slice:        []string{"a", "b", "c"},
+		ComplexSlice: endpoints,
+		Map:          hosts,
+		Inline:       &Mixin{MixedIn: "value"},
+	}
+}
+
+func BenchmarkEncode(b *testing.B) {
+	value := benchmarkConfig()
+
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := NewEncoder(value).Encode(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkEncodeDocs(b *testing.B) {
+	value := benchmarkConfig()
+
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := NewEncoder(value, WithComments(CommentsDocs)).Encode(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkEncodeExamples(b *testing.B) {
+	value := &FakeConfig{}
+
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := NewEncoder(value).Encode(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMarshal(b *testing.B) {
+	value := benchmarkConfig()
+	encoder := NewEncoder(value, WithComments(CommentsDocs))
+
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := encoder.Marshal(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 func TestEncoderSuite(t *testing.T) {
 	suite.Run(t, &EncoderSuite{})
 }
@@ -0,0 +1,232 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package encodertest provides helpers to test generated documentation.
+package encodertest
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/projectdiscovery/yamldoc-go/encoder"
+	yaml "gopkg.in/yaml.v3"
+)
+
+// VerifyExamples checks that every example documented for the types of the
+// values, and for the documented struct types reachable from their fields,
+// round-trips through the encoder into the documented Go type.
+func VerifyExamples(t testing.TB, values ...encoder.Documented) {
+	t.Helper()
+
+	visited := map[reflect.Type]bool{}
+
+	for _, value := range values {
+		verifyType(t, reflect.TypeOf(value), visited)
+	}
+}
+
+func verifyType(t testing.TB, typ reflect.Type, visited map[reflect.Type]bool) {
+	t.Helper()
+
+	typ = structType(typ)
+	if typ == nil || visited[typ] {
+		return
+	}
+
+	visited[typ] = true
+
+	doc := typeDoc(typ)
+	if doc == nil {
+		doc = &encoder.Doc{}
+	}
+
+	path := doc.Type
+	if path == "" {
+		path = typ.Name()
+	}
+
+	verifyExamples(t, path, typ, doc.Examples)
+
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+
+		// skip unexported fields
+		if field.PkgPath != "" {
+			continue
+		}
+
+		// field docs are matched by the field index, as in the encoder
+		if fieldDoc := doc.Field(i); fieldDoc != nil {
+			verifyExamples(t, fmt.Sprintf("%s.%s", path, fieldDoc.Name), field.Type, fieldDoc.Examples)
+		}
+
+		verifyType(t, field.Type, visited)
+	}
+}
+
+func verifyExamples(t testing.TB, path string, typ reflect.Type, examples []*encoder.Example) {
+	t.Helper()
+
+	for i, example := range examples {
+		if err := VerifyExample(typ, example, i); err != nil {
+			t.Errorf("%s: example #%d %q: %s", path, i, example.GetName(), err)
+		}
+	}
+}
+
+// VerifyExample encodes the example populated at the index as rendered by the
+// encoder and checks that it decodes back into an equal value of the type
+// with strict known fields. Nil and empty slices and maps are considered equal,
+// as they are indistinguishable once encoded.
+func VerifyExample(typ reflect.Type, example *encoder.Example, index int) error {
+	value := example.Populated(index)
+	if value == nil {
+		return nil
+	}
+
+	expected := reflect.ValueOf(value)
+
+	// interface fields are decoded into the type of the example
+	if typ.Kind() == reflect.Interface {
+		typ = expected.Type()
+	}
+
+	if indirect(expected.Type()) != indirect(typ) {
+		return fmt.Errorf("example type %s does not match the type %s", expected.Type(), typ)
+	}
+
+	node, err := encoder.NewEncoder(value).Marshal()
+	if err != nil {
+		return fmt.Errorf("encoding failed: %w", err)
+	}
+
+	data, err := yaml.Marshal(node)
+	if err != nil {
+		return fmt.Errorf("encoding failed: %w", err)
+	}
+
+	target := reflect.New(typ)
+
+	if err = encoder.DecodeExample(target.Interface(), string(data)); err != nil {
+		return fmt.Errorf("decoding failed: %w\n%s", err, data)
+	}
+
+	if !equal(expected, target.Elem()) {
+		actual, _ := yaml.Marshal(target.Elem().Interface()) //nolint:errcheck
+
+		return fmt.Errorf("decoded value differs:\nencoded:\n%s\ndecoded:\n%s", data, actual)
+	}
+
+	return nil
+}
+
+// typeDoc returns the docs of the struct type.
+func typeDoc(typ reflect.Type) *encoder.Doc {
+	if documented, ok := reflect.New(typ).Interface().(encoder.Documented); ok {
+		return documented.Doc()
+	}
+
+	return nil
+}
+
+// structType returns the struct type stored in pointers, slices, arrays and
+// maps of the type, or nil.
+func structType(typ reflect.Type) reflect.Type {
+	for {
+		//nolint:exhaustive
+		switch typ.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+			typ = typ.Elem()
+		case reflect.Struct:
+			return typ
+		default:
+			return nil
+		}
+	}
+}
+
+// indirect strips pointers from the type.
+func indirect(typ reflect.Type) reflect.Type {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	return typ
+}
+
+// equal compares the values dereferencing pointers, nil and empty slices and
+// maps are equal.
+//
+//nolint:gocyclo,exhaustive
+func equal(a, b reflect.Value) bool {
+	for a.Kind() == reflect.Ptr && !a.IsNil() {
+		a = a.Elem()
+	}
+
+	for b.Kind() == reflect.Ptr && !b.IsNil() {
+		b = b.Elem()
+	}
+
+	if !a.IsValid() || !b.IsValid() {
+		return a.IsValid() == b.IsValid()
+	}
+
+	if a.Type() != b.Type() {
+		return false
+	}
+
+	switch a.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+
+		return equal(a.Elem(), b.Elem())
+	case reflect.Map:
+		if a.Len() != b.Len() {
+			return false
+		}
+
+		for _, key := range a.MapKeys() {
+			if other := b.MapIndex(key); !other.IsValid() || !equal(a.MapIndex(key), other) {
+				return false
+			}
+		}
+
+		return true
+	case reflect.Slice, reflect.Array:
+		if a.Len() != b.Len() {
+			return false
+		}
+
+		for i := 0; i < a.Len(); i++ {
+			if !equal(a.Index(i), b.Index(i)) {
+				return false
+			}
+		}
+
+		return true
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			if !equal(a.Field(i), b.Field(i)) {
+				return false
+			}
+		}
+
+		return true
+	case reflect.Bool:
+		return a.Bool() == b.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() == b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return a.Uint() == b.Uint()
+	case reflect.Float32, reflect.Float64:
+		return a.Float() == b.Float()
+	case reflect.String:
+		return a.String() == b.String()
+	default:
+		return a.CanInterface() && b.CanInterface() && reflect.DeepEqual(a.Interface(), b.Interface())
+	}
+}
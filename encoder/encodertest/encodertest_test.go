@@ -0,0 +1,131 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package encodertest
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/projectdiscovery/yamldoc-go/encoder"
+	"github.com/stretchr/testify/assert"
+)
+
+type Server struct {
+	Host    string   `yaml:"host"`
+	Port    int      `yaml:"port,omitempty"`
+	Aliases []string `yaml:"aliases,omitempty"`
+	Broken  *Broken  `yaml:"broken,omitempty"`
+}
+
+type Broken struct {
+	Value string `yaml:"value"`
+}
+
+// MarshalYAML renders a key which can't be decoded back.
+func (b *Broken) MarshalYAML() (interface{}, error) {
+	return map[string]string{"other": b.Value}, nil
+}
+
+var serverDoc encoder.Doc
+
+func init() {
+	serverDoc.Type = "Server"
+	serverDoc.AddExample("full server", &Server{
+		Host:    "127.0.0.1",
+		Port:    8080,
+		Aliases: []string{"localhost"},
+	})
+	serverDoc.Fields = make([]encoder.Doc, 4)
+	serverDoc.Fields[0].Name = "host"
+	serverDoc.Fields[0].AddExample("", "example.com")
+	serverDoc.Fields[1].Name = "port"
+	serverDoc.Fields[1].AddExample("", 443)
+	serverDoc.Fields[2].Name = "aliases"
+	serverDoc.Fields[2].AddExample("", []string{})
+	serverDoc.Fields[3].Name = "broken"
+}
+
+func (Server) Doc() *encoder.Doc {
+	return &serverDoc
+}
+
+type recorder struct {
+	testing.TB
+
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+type Cached struct {
+	Name  string `yaml:"name"`
+	Cache string `yaml:"-"`
+}
+
+type Mismatch struct {
+	Port   int32   `yaml:"port"`
+	Broken *Broken `yaml:"broken"`
+}
+
+var mismatchDoc encoder.Doc
+
+func init() {
+	mismatchDoc.Type = "Mismatch"
+	mismatchDoc.Fields = make([]encoder.Doc, 2)
+	mismatchDoc.Fields[0].Name = "port"
+	mismatchDoc.Fields[0].AddExample("wide port", int64(443))
+	mismatchDoc.Fields[1].Name = "broken"
+	mismatchDoc.Fields[1].AddExample("broken example", &Broken{Value: "abc"})
+}
+
+func (Mismatch) Doc() *encoder.Doc {
+	return &mismatchDoc
+}
+
+func TestVerifyExamples(t *testing.T) {
+	VerifyExamples(t, Server{})
+}
+
+func TestVerifyExamplesReportsFailures(t *testing.T) {
+	r := &recorder{TB: t}
+	VerifyExamples(r, &Mismatch{})
+
+	if assert.Len(t, r.errors, 2) {
+		assert.Equal(t, `Mismatch.port: example #0 "wide port": example type int64 does not match the type int32`, r.errors[0])
+		assert.Contains(t, r.errors[1], `Mismatch.broken: example #0 "broken example": decoding failed`)
+	}
+}
+
+func TestVerifyExamplePopulated(t *testing.T) {
+	var doc encoder.Doc
+
+	doc.AddExample("partial server", &Server{Host: "localhost"})
+	doc.AddExample("padded host", "  padded")
+
+	// the populated value rendered by the encoder is verified
+	assert.NoError(t, VerifyExample(reflect.TypeOf(&Server{}), doc.Examples[0], 0))
+
+	populated, ok := doc.Examples[0].Populated(0).(*Server)
+	if assert.True(t, ok) {
+		assert.Equal(t, 443, populated.Port)
+	}
+
+	assert.NoError(t, VerifyExample(reflect.TypeOf(""), doc.Examples[1], 1))
+
+	// values lost in the round-trip are reported even if the yaml is the same
+	doc.AddExample("skipped field", Cached{Name: "a", Cache: "b"})
+
+	err := VerifyExample(reflect.TypeOf(Cached{}), doc.Examples[2], 2)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "decoded value differs")
+	}
+
+	assert.EqualError(t, VerifyExample(reflect.TypeOf(0), doc.Examples[1], 1), "example type string does not match the type int")
+}
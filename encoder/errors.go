@@ -0,0 +1,116 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package encoder
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// EncodeError is returned when a value can't be encoded.
+type EncodeError struct {
+	// Path is the dotted path of the value, e.g. "jobs.0.credentials", empty for the root value.
+	Path string
+	// Type is the Go type of the value.
+	Type reflect.Type
+	Err  error
+}
+
+func (e *EncodeError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("failed to encode %v: %s", e.Type, e.Err)
+	}
+
+	return fmt.Sprintf("failed to encode %v at %q: %s", e.Type, e.Path, e.Err)
+}
+
+func (e *EncodeError) Unwrap() error {
+	return e.Err
+}
+
+// ExampleError is reported when a field example can't be rendered.
+type ExampleError struct {
+	// Path is the dotted path of the documented field.
+	Path string
+	// Index is the index of the example in the field docs.
+	Index int
+	// Name is the example name.
+	Name string
+	Err  error
+}
+
+func (e *ExampleError) Error() string {
+	return fmt.Sprintf("failed to render example %d of %q: %s", e.Index, e.Path, e.Err)
+}
+
+func (e *ExampleError) Unwrap() error {
+	return e.Err
+}
+
+// Warnings collects examples skipped by the encoder, it is safe for concurrent use.
+type Warnings struct {
+	mu       sync.Mutex
+	examples []*ExampleError
+}
+
+// Examples returns the errors of the skipped examples.
+func (w *Warnings) Examples() []*ExampleError {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return append([]*ExampleError(nil), w.examples...)
+}
+
+// Reset drops the collected warnings.
+func (w *Warnings) Reset() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.examples = nil
+}
+
+func (w *Warnings) addExample(err *ExampleError) {
+	if w == nil {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.examples = append(w.examples, err)
+}
+
+// encodeError returns the error located at the path of the encoded value.
+func (s *encodeState) encodeError(in interface{}, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &EncodeError{
+		Path: strings.Join(s.path, "."),
+		Type: reflect.TypeOf(in),
+		Err:  err,
+	}
+}
+
+// exampleError reports the example error, it is returned only if the options fail on example errors.
+func exampleError(path []string, index int, example *Example, err error, options *Options) error {
+	exampleErr := &ExampleError{
+		Path:  strings.Join(path, "."),
+		Index: index,
+		Name:  example.GetName(),
+		Err:   err,
+	}
+
+	if options.FailOnExampleErrors {
+		return exampleErr
+	}
+
+	options.Warnings.addExample(exampleErr)
+
+	return nil
+}
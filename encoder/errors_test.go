@@ -0,0 +1,112 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package encoder
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errBrokenValue = errors.New("broken value")
+
+type Broken struct {
+	Fail bool
+}
+
+func (b Broken) MarshalYAML() (interface{}, error) {
+	if b.Fail {
+		return nil, errBrokenValue
+	}
+
+	return "ok", nil
+}
+
+type Check struct {
+	Name  string  `yaml:"name"`
+	Probe *Broken `yaml:"probe,omitempty"`
+}
+
+type Monitor struct {
+	Checks []Check `yaml:"checks"`
+	Target *Broken `yaml:"target,omitempty"`
+}
+
+var monitorDoc = &Doc{
+	Type: "Monitor",
+	Fields: []Doc{
+		{Name: "checks"},
+		{Name: "target"},
+	},
+}
+
+func init() {
+	monitorDoc.Fields[1].AddExample("valid", &Broken{})
+	monitorDoc.Fields[1].AddExample("broken", &Broken{Fail: true})
+}
+
+func (m Monitor) Doc() *Doc {
+	return monitorDoc
+}
+
+func TestEncodeError(t *testing.T) {
+	value := &Monitor{
+		Checks: []Check{{Name: "first"}, {Name: "second", Probe: &Broken{Fail: true}}},
+	}
+
+	for _, options := range [][]Option{
+		nil,
+		{WithComments(CommentsDisabled)},
+	} {
+		_, err := NewEncoder(value, options...).Encode()
+		require.Error(t, err)
+
+		var encodeErr *EncodeError
+		require.True(t, errors.As(err, &encodeErr))
+
+		assert.Equal(t, "checks.1.probe", encodeErr.Path)
+		assert.Equal(t, reflect.TypeOf(&Broken{}), encodeErr.Type)
+		assert.ErrorIs(t, err, errBrokenValue)
+		assert.EqualError(t, err, `failed to encode *encoder.Broken at "checks.1.probe": broken value`)
+	}
+}
+
+func TestExampleErrors(t *testing.T) {
+	value := &Monitor{}
+
+	warnings := &Warnings{}
+
+	data, err := NewEncoder(value, WithWarnings(warnings)).Encode()
+	require.NoError(t, err)
+
+	assert.Contains(t, string(data), "# valid")
+	assert.NotContains(t, string(data), "# broken")
+
+	require.Len(t, warnings.Examples(), 1)
+
+	warning := warnings.Examples()[0]
+	assert.Equal(t, "target", warning.Path)
+	assert.Equal(t, 1, warning.Index)
+	assert.Equal(t, "broken", warning.Name)
+	assert.ErrorIs(t, warning, errBrokenValue)
+
+	warnings.Reset()
+	assert.Empty(t, warnings.Examples())
+
+	_, err = NewEncoder(value, WithFailOnExampleErrors(), WithWarnings(warnings)).Encode()
+	require.Error(t, err)
+
+	var exampleErr *ExampleError
+	require.True(t, errors.As(err, &exampleErr))
+	assert.Equal(t, "target", exampleErr.Path)
+
+	var encodeErr *EncodeError
+	require.True(t, errors.As(err, &encodeErr))
+	assert.Equal(t, "target", encodeErr.Path)
+	assert.Empty(t, warnings.Examples())
+}
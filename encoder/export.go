@@ -0,0 +1,297 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package encoder
+
+import (
+	"encoding/json"
+	"fmt"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// ExportVersion is the version of the documentation export format.
+const ExportVersion = 1
+
+// DocExport is a machine-readable representation of file docs which can
+// be consumed without compiling the documented go code.
+type DocExport struct {
+	Version int             `json:"version" yaml:"version"`
+	Files   []*ExportedFile `json:"files" yaml:"files"`
+}
+
+// ExportedFile is the serializable form of a FileDoc.
+type ExportedFile struct {
+	Name        string         `json:"name" yaml:"name"`
+	Description string         `json:"description,omitempty" yaml:"description,omitempty"`
+	Weight      int            `json:"weight,omitempty" yaml:"weight,omitempty"`
+	Structs     []*ExportedDoc `json:"structs" yaml:"structs"`
+}
+
+// ExportedDoc is the serializable form of a Doc.
+type ExportedDoc struct {
+	Name            string               `json:"name,omitempty" yaml:"name,omitempty"`
+	Type            string               `json:"type,omitempty" yaml:"type,omitempty"`
+	Description     string               `json:"description,omitempty" yaml:"description,omitempty"`
+	Comments        ExportedComments     `json:"comments" yaml:"comments"`
+	Values          []string             `json:"values,omitempty" yaml:"values,omitempty"`
+	EnumFields      []string             `json:"enum_fields,omitempty" yaml:"enum_fields,omitempty"`
+	Note            string               `json:"note,omitempty" yaml:"note,omitempty"`
+	Required        bool                 `json:"required,omitempty" yaml:"required,omitempty"`
+	Default         string               `json:"default,omitempty" yaml:"default,omitempty"`
+	Priority        int                  `json:"priority,omitempty" yaml:"priority,omitempty"`
+	Sensitive       bool                 `json:"sensitive,omitempty" yaml:"sensitive,omitempty"`
+	Style           string               `json:"style,omitempty" yaml:"style,omitempty"`
+	AppearsIn       []ExportedAppearance `json:"appears_in,omitempty" yaml:"appears_in,omitempty"`
+	PartDefinitions []ExportedKeyValue   `json:"part_definitions,omitempty" yaml:"part_definitions,omitempty"`
+	Examples        []ExportedExample    `json:"examples,omitempty" yaml:"examples,omitempty"`
+	Fields          []*ExportedDoc       `json:"fields,omitempty" yaml:"fields,omitempty"`
+}
+
+// ExportedComments holds the yaml comments of a Doc.
+type ExportedComments struct {
+	Head string `json:"head,omitempty" yaml:"head,omitempty"`
+	Line string `json:"line,omitempty" yaml:"line,omitempty"`
+	Foot string `json:"foot,omitempty" yaml:"foot,omitempty"`
+}
+
+// ExportedAppearance is the serializable form of an Appearance.
+type ExportedAppearance struct {
+	TypeName  string `json:"type_name" yaml:"type_name"`
+	FieldName string `json:"field_name" yaml:"field_name"`
+}
+
+// ExportedKeyValue is the serializable form of a KeyValue.
+type ExportedKeyValue struct {
+	Key   string `json:"key" yaml:"key"`
+	Value string `json:"value" yaml:"value"`
+}
+
+// ExportedExample holds an example rendered as a yaml string with comments.
+type ExportedExample struct {
+	Name  string `json:"name,omitempty" yaml:"name,omitempty"`
+	Value string `json:"value" yaml:"value"`
+}
+
+// ExportDocs converts file docs into their serializable form.
+func ExportDocs(docs ...*FileDoc) (*DocExport, error) {
+	export := &DocExport{
+		Version: ExportVersion,
+		Files:   make([]*ExportedFile, 0, len(docs)),
+	}
+
+	for _, fd := range docs {
+		file := &ExportedFile{
+			Name:        fd.Name,
+			Description: fd.Description,
+			Weight:      fd.Weight,
+			Structs:     make([]*ExportedDoc, 0, len(fd.Structs)),
+		}
+
+		for _, s := range fd.Structs {
+			exported, err := exportDoc(s)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", s.Type, err)
+			}
+
+			file.Structs = append(file.Structs, exported)
+		}
+
+		export.Files = append(export.Files, file)
+	}
+
+	return export, nil
+}
+
+// ExportJSON exports file docs as JSON.
+func ExportJSON(docs ...*FileDoc) ([]byte, error) {
+	export, err := ExportDocs(docs...)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(export, "", "  ")
+}
+
+// ExportYAML exports file docs as YAML.
+func ExportYAML(docs ...*FileDoc) ([]byte, error) {
+	export, err := ExportDocs(docs...)
+	if err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(export)
+}
+
+// LoadDocExport parses a JSON or YAML documentation export.
+func LoadDocExport(data []byte) (*DocExport, error) {
+	export := &DocExport{}
+
+	// JSON is a subset of YAML, so both formats are handled by the yaml decoder
+	if err := yaml.Unmarshal(data, export); err != nil {
+		return nil, err
+	}
+
+	if export.Version > ExportVersion {
+		return nil, fmt.Errorf("unsupported export version %d", export.Version)
+	}
+
+	return export, nil
+}
+
+// LoadFileDocs parses a JSON or YAML documentation export and reconstructs file docs.
+//
+// Examples of the reconstructed docs hold *yaml.Node values.
+func LoadFileDocs(data []byte) ([]*FileDoc, error) {
+	export, err := LoadDocExport(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return export.FileDocs()
+}
+
+// FileDocs reconstructs file docs from the export.
+func (e *DocExport) FileDocs() ([]*FileDoc, error) {
+	docs := make([]*FileDoc, 0, len(e.Files))
+
+	for _, file := range e.Files {
+		fd := &FileDoc{
+			Name:        file.Name,
+			Description: file.Description,
+			Weight:      file.Weight,
+			Structs:     make([]*Doc, 0, len(file.Structs)),
+		}
+
+		for _, s := range file.Structs {
+			doc, err := importDoc(s)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", s.Type, err)
+			}
+
+			fd.Structs = append(fd.Structs, doc)
+		}
+
+		docs = append(docs, fd)
+	}
+
+	return docs, nil
+}
+
+func exportDoc(doc *Doc) (*ExportedDoc, error) {
+	res := &ExportedDoc{
+		Name:        doc.Name,
+		Type:        doc.Type,
+		Description: doc.Description,
+		Comments: ExportedComments{
+			Head: doc.Comments[HeadComment],
+			Line: doc.Comments[LineComment],
+			Foot: doc.Comments[FootComment],
+		},
+		Values:     doc.Values,
+		EnumFields: doc.EnumFields,
+		Note:       doc.Note,
+		Required:   doc.Required,
+		Default:    doc.Default,
+		Priority:   doc.Priority,
+		Sensitive:  doc.Sensitive,
+		Style:      doc.Style,
+	}
+
+	for _, appearance := range doc.AppearsIn {
+		res.AppearsIn = append(res.AppearsIn, ExportedAppearance(appearance))
+	}
+
+	for _, value := range doc.PartDefinitions {
+		res.PartDefinitions = append(res.PartDefinitions, ExportedKeyValue(value))
+	}
+
+	for _, example := range doc.Examples {
+		// exports are published with the docs, so they never hold sensitive values
+		node, err := ExampleNode(doc, example, WithRedaction(RedactReplace))
+		if err != nil {
+			return nil, fmt.Errorf("example %q: %w", example.GetName(), err)
+		}
+
+		data, err := yaml.Marshal(node)
+		if err != nil {
+			return nil, fmt.Errorf("example %q: %w", example.GetName(), err)
+		}
+
+		res.Examples = append(res.Examples, ExportedExample{
+			Name:  example.GetName(),
+			Value: string(data),
+		})
+	}
+
+	for i := range doc.Fields {
+		field, err := exportDoc(&doc.Fields[i])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", doc.Fields[i].Name, err)
+		}
+
+		res.Fields = append(res.Fields, field)
+	}
+
+	return res, nil
+}
+
+func importDoc(exported *ExportedDoc) (*Doc, error) {
+	doc := &Doc{
+		Name:        exported.Name,
+		Type:        exported.Type,
+		Description: exported.Description,
+		Values:      exported.Values,
+		EnumFields:  exported.EnumFields,
+		Note:        exported.Note,
+		Required:    exported.Required,
+		Default:     exported.Default,
+		Priority:    exported.Priority,
+		Sensitive:   exported.Sensitive,
+		Style:       exported.Style,
+	}
+
+	doc.Comments[HeadComment] = exported.Comments.Head
+	doc.Comments[LineComment] = exported.Comments.Line
+	doc.Comments[FootComment] = exported.Comments.Foot
+
+	for _, appearance := range exported.AppearsIn {
+		doc.AppearsIn = append(doc.AppearsIn, Appearance(appearance))
+	}
+
+	for _, value := range exported.PartDefinitions {
+		doc.PartDefinitions = append(doc.PartDefinitions, KeyValue(value))
+	}
+
+	for _, example := range exported.Examples {
+		var node yaml.Node
+
+		if err := yaml.Unmarshal([]byte(example.Value), &node); err != nil {
+			return nil, fmt.Errorf("example %q: %w", example.Name, err)
+		}
+
+		// unwrap the document node
+		value := &node
+		if node.Kind == yaml.DocumentNode && len(node.Content) == 1 {
+			value = node.Content[0]
+		}
+
+		doc.AddExample(example.Name, value)
+	}
+
+	if len(exported.Fields) > 0 {
+		doc.Fields = make([]Doc, 0, len(exported.Fields))
+	}
+
+	for _, f := range exported.Fields {
+		field, err := importDoc(f)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Name, err)
+		}
+
+		doc.Fields = append(doc.Fields, *field)
+	}
+
+	return doc, nil
+}
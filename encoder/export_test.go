@@ -0,0 +1,72 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package encoder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportRoundTrip(t *testing.T) {
+	fd := testFileDoc()
+	fd.Description = "Job configuration."
+	fd.Structs[0].Fields[0].Comments[LineComment] = "Name of the job"
+	fd.Structs[0].Fields[2].AddExample("Options Example", &Endpoint{Host: "localhost", Port: 80})
+	fd.Structs[1].PartDefinitions = []KeyValue{{Key: "body", Value: "response body"}}
+
+	expected, err := fd.Encode()
+	require.NoError(t, err)
+
+	for name, export := range map[string]func(...*FileDoc) ([]byte, error){
+		"json": ExportJSON,
+		"yaml": ExportYAML,
+	} {
+		data, err := export(fd)
+		require.NoError(t, err, name)
+
+		docs, err := LoadFileDocs(data)
+		require.NoError(t, err, name)
+		require.Len(t, docs, 1, name)
+
+		loaded := docs[0]
+		assert.Equal(t, "Job configuration.", loaded.Description, name)
+		assert.Equal(t, "Name of the job", loaded.Structs[0].Fields[0].Comments[LineComment], name)
+		assert.True(t, loaded.Structs[0].Fields[0].Required, name)
+		assert.Equal(t, []Appearance{{TypeName: "Job", FieldName: "internal-options"}}, loaded.Structs[1].AppearsIn, name)
+
+		actual, err := loaded.Encode()
+		require.NoError(t, err, name)
+		assert.Equal(t, string(expected), string(actual), name)
+	}
+}
+
+func TestExportJSON(t *testing.T) {
+	var doc Doc
+
+	doc.Type = "Endpoint"
+	doc.AddExample("local", &Endpoint{Host: "localhost"})
+
+	data, err := ExportJSON(&FileDoc{Name: "Endpoint", Structs: []*Doc{&doc}})
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"version": 1,
+		"files": [{
+			"name": "Endpoint",
+			"structs": [{
+				"type": "Endpoint",
+				"comments": {},
+				"examples": [{"name": "local", "value": "host: localhost # endpoint host\n"}]
+			}]
+		}]
+	}`, string(data))
+}
+
+func TestLoadDocExportVersion(t *testing.T) {
+	_, err := LoadDocExport([]byte(`{"version": 100, "files": []}`))
+	assert.Error(t, err)
+}
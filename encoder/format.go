@@ -0,0 +1,209 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package encoder
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// defaultIndent is the indentation used by yaml.Marshal.
+const defaultIndent = 4
+
+func (o *Options) indent() int {
+	if o.Indent > 0 {
+		return o.Indent
+	}
+
+	return defaultIndent
+}
+
+// exampleIndent returns the indentation of examples rendered for empty fields.
+func (o *Options) exampleIndent() string {
+	if o.Indent > 0 {
+		return strings.Repeat(" ", o.Indent)
+	}
+
+	return "  "
+}
+
+// marshalYaml marshals the value using indentation and sequence style options.
+func marshalYaml(in interface{}, options *Options) ([]byte, error) {
+	var buf bytes.Buffer
+
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(options.indent())
+
+	if err := enc.Encode(in); err != nil {
+		return nil, err
+	}
+
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	data := buf.Bytes()
+
+	if options.CompactSequences {
+		data = compactSequences(data, options.indent())
+	}
+
+	return data, nil
+}
+
+var blockScalarRe = regexp.MustCompile(`(^|:|-)\s*[|>][-+0-9]*$`)
+
+// compactSequences moves sequences nested in mappings two spaces left, so the
+// "- " indicator becomes a part of the indentation. Block scalars and comments
+// are kept intact.
+func compactSequences(data []byte, indent int) []byte {
+	if indent < 2 {
+		return data
+	}
+
+	lines := strings.Split(string(data), "\n")
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(stripComment(line))
+		item, depth := nodeIndent(line)
+
+		// skip block scalar contents, indented more than the key or the sequence item
+		if blockScalarRe.MatchString(trimmed) {
+			if strings.IndexByte("|>", line[depth]) >= 0 {
+				depth = item
+			}
+
+			for i+1 < len(lines) && (strings.TrimSpace(lines[i+1]) == "" || lineIndent(lines[i+1]) > depth) {
+				i++
+			}
+
+			continue
+		}
+
+		if !strings.HasSuffix(trimmed, ":") {
+			continue
+		}
+
+		next := i + 1
+		for next < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[next]), "#") {
+			next++
+		}
+
+		if next == len(lines) || lineIndent(lines[next]) != depth+indent || !isSequenceItem(lines[next]) {
+			continue
+		}
+
+		for j := i + 1; j < len(lines); j++ {
+			if strings.TrimSpace(lines[j]) == "" {
+				continue
+			}
+
+			if lineIndent(lines[j]) < depth+indent {
+				break
+			}
+
+			lines[j] = lines[j][2:]
+		}
+	}
+
+	return []byte(strings.Join(lines, "\n"))
+}
+
+// stripComment removes the comment from the line. Quoted scalars may contain '#'.
+func stripComment(line string) string {
+	var quote byte
+
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+
+		switch {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			// quotes start a scalar only at the beginning of a token
+			if i == 0 || strings.IndexByte(" [{,", line[i-1]) >= 0 {
+				quote = c
+			}
+		case c == '#':
+			if i == 0 || line[i-1] == ' ' {
+				return line[:i]
+			}
+		}
+	}
+
+	return line
+}
+
+func isSequenceItem(line string) bool {
+	trimmed := strings.TrimSpace(line)
+
+	return trimmed == "-" || strings.HasPrefix(trimmed, "- ")
+}
+
+// nodeIndent returns the column of the last sequence item indicator and the
+// column of the node following the indicators on the line.
+func nodeIndent(line string) (item, node int) {
+	node = lineIndent(line)
+	item = node
+
+	for rest := line[node:]; rest == "-" || strings.HasPrefix(rest, "- "); {
+		item = node
+		trimmed := strings.TrimLeft(rest[1:], " ")
+		node += len(rest) - len(trimmed)
+		rest = trimmed
+	}
+
+	return item, node
+}
+
+func lineIndent(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// foldComment folds comment text to lines no longer than the width.
+func foldComment(text string, width int) string {
+	if width <= 0 || text == "" {
+		return text
+	}
+
+	// leave space for the "# " prefix
+	return strings.Join(wrapText(text, width-2), "\n")
+}
+
+// quoteScalar applies the quote style to string scalar values.
+func quoteScalar(node *yaml.Node, options *Options) {
+	if node.Kind != yaml.ScalarNode || node.ShortTag() != "!!str" {
+		return
+	}
+
+	// multiline strings get the literal style from yaml.v3
+	if strings.Contains(node.Value, "\n") {
+		if node.Style == 0 || node.Style == yaml.LiteralStyle {
+			node.Style = options.Multiline.style()
+		}
+
+		return
+	}
+
+	if node.Style != 0 {
+		return
+	}
+
+	switch options.QuoteStyle {
+	case QuoteDouble:
+		node.Style = yaml.DoubleQuotedStyle
+	case QuoteSingle:
+		node.Style = yaml.SingleQuotedStyle
+	case QuoteAuto:
+	}
+}
@@ -0,0 +1,99 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package encoder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v3"
+)
+
+func TestCompactSequences(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{
+			name: "literals",
+			source: `steps:
+  - |
+    run:
+    - make
+  - script: |-
+      list:
+      - one
+    args:
+      - -v
+`,
+			expected: `steps:
+- |
+  run:
+  - make
+- script: |-
+    list:
+    - one
+  args:
+  - -v
+`,
+		},
+		{
+			name: "comments",
+			source: `# head
+steps: # the steps: |
+  # first step
+  - build # build: - it
+  # second step
+  - test
+"quoted # key:": "value # with: |"
+after:
+  - done
+`,
+			expected: `# head
+steps: # the steps: |
+# first step
+- build # build: - it
+# second step
+- test
+"quoted # key:": "value # with: |"
+after:
+- done
+`,
+		},
+		{
+			name: "nested",
+			source: `matrix:
+  - - a
+    - b
+  - os: # targets
+      - linux
+`,
+			expected: `matrix:
+- - a
+  - b
+- os: # targets
+  - linux
+`,
+		},
+	} {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			var node yaml.Node
+			require.NoError(t, yaml.Unmarshal([]byte(test.source), &node))
+
+			data, err := marshalYaml(&node, &Options{Indent: 2, CompactSequences: true})
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, string(data))
+
+			var expected, actual interface{}
+			require.NoError(t, yaml.Unmarshal([]byte(test.source), &expected))
+			require.NoError(t, yaml.Unmarshal(data, &actual))
+			assert.Equal(t, expected, actual)
+		})
+	}
+}
@@ -0,0 +1,188 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package encoder
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+var idRe = regexp.MustCompile(`[^a-z0-9_-]+`)
+
+type hugoFrontmatter struct {
+	Title       string `yaml:"title"`
+	Weight      int    `yaml:"weight"`
+	Description string `yaml:"description,omitempty"`
+}
+
+type docusaurusFrontmatter struct {
+	ID              string `yaml:"id"`
+	Title           string `yaml:"title"`
+	SidebarPosition int    `yaml:"sidebar_position"`
+	Description     string `yaml:"description,omitempty"`
+}
+
+// HugoFrontmatter returns Hugo frontmatter for the file documentation,
+// the weight defines the position of the page in the menu.
+func (fd *FileDoc) HugoFrontmatter() (string, error) {
+	return formatFrontmatter(&hugoFrontmatter{
+		Title:       fd.Name,
+		Weight:      fd.Weight,
+		Description: fd.summary(),
+	})
+}
+
+// DocusaurusFrontmatter returns Docusaurus frontmatter for the file documentation,
+// the weight defines the position of the page in the sidebar.
+func (fd *FileDoc) DocusaurusFrontmatter() (string, error) {
+	id, err := fd.pageID()
+	if err != nil {
+		return "", err
+	}
+
+	return formatFrontmatter(&docusaurusFrontmatter{
+		ID:              id,
+		Title:           fd.Name,
+		SidebarPosition: fd.Weight,
+		Description:     fd.summary(),
+	})
+}
+
+// DocusaurusSidebar returns sidebars.js contents listing the file docs
+// written to dir ordered by their weight.
+func DocusaurusSidebar(sidebar, dir string, docs ...*FileDoc) (string, error) {
+	ids, err := uniqueNames(docs, (*FileDoc).pageID)
+	if err != nil {
+		return "", err
+	}
+
+	items := make([]string, 0, len(docs))
+
+	for _, fd := range byWeight(docs) {
+		items = append(items, path.Join(dir, ids[fd]))
+	}
+
+	data, err := json.MarshalIndent(map[string][]string{sidebar: items}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("module.exports = %s;\n", data), nil
+}
+
+// MkDocsNav returns mkdocs.yml nav entries listing the file docs written
+// to dir under the section ordered by their weight. The options must match
+// the ones used to write the docs, as they define the file names.
+func MkDocsNav(section, dir string, docs []*FileDoc, opts ...RenderOption) ([]byte, error) {
+	names, err := fileNames(docs, newRenderOptions(opts...))
+	if err != nil {
+		return nil, err
+	}
+
+	pages := make([]map[string]string, 0, len(docs))
+
+	for _, fd := range byWeight(docs) {
+		pages = append(pages, map[string]string{
+			fd.Name: path.Join(dir, names[fd]),
+		})
+	}
+
+	return yaml.Marshal(map[string]interface{}{
+		"nav": []map[string]interface{}{
+			{section: pages},
+		},
+	})
+}
+
+// byWeight returns the file docs sorted by weight, docs with the same weight keep their order.
+func byWeight(docs []*FileDoc) []*FileDoc {
+	res := append([]*FileDoc(nil), docs...)
+
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Weight < res[j].Weight
+	})
+
+	return res
+}
+
+// id returns the slugified name used in page ids and slug file names.
+func (fd *FileDoc) id() string {
+	return strings.Trim(idRe.ReplaceAllString(strings.ToLower(fd.Name), "-"), "-")
+}
+
+// pageID returns the page id, empty ids are rejected.
+func (fd *FileDoc) pageID() (string, error) {
+	id := fd.id()
+	if id == "" {
+		return "", fmt.Errorf("file doc %q has an empty id", fd.Name)
+	}
+
+	return id, nil
+}
+
+// fileName returns the name of the file written by Write, the lowercase name
+// or the id with WithSlugFileNames.
+func (fd *FileDoc) fileName(options *RenderOptions) (string, error) {
+	name := strings.ToLower(fd.Name)
+	if options.SlugFileNames {
+		name = fd.id()
+	}
+
+	if name == "" {
+		return "", fmt.Errorf("file doc %q has an empty file name", fd.Name)
+	}
+
+	return fmt.Sprintf("%s.%s", name, "md"), nil
+}
+
+// fileNames returns the names of the files written for the docs.
+func fileNames(docs []*FileDoc, options *RenderOptions) (map[*FileDoc]string, error) {
+	return uniqueNames(docs, func(fd *FileDoc) (string, error) {
+		return fd.fileName(options)
+	})
+}
+
+// uniqueNames returns the names of the docs, docs with the same name are rejected
+// as their pages would overwrite each other.
+func uniqueNames(docs []*FileDoc, name func(*FileDoc) (string, error)) (map[*FileDoc]string, error) {
+	res := make(map[*FileDoc]string, len(docs))
+	seen := make(map[string]*FileDoc, len(docs))
+
+	for _, fd := range docs {
+		n, err := name(fd)
+		if err != nil {
+			return nil, err
+		}
+
+		if other, ok := seen[n]; ok {
+			return nil, fmt.Errorf("file docs %q and %q have the same name %q", other.Name, fd.Name, n)
+		}
+
+		seen[n] = fd
+		res[fd] = n
+	}
+
+	return res, nil
+}
+
+// summary returns the first line of the file description.
+func (fd *FileDoc) summary() string {
+	return strings.TrimSpace(strings.Split(strings.TrimSpace(fd.Description), "\n")[0])
+}
+
+func formatFrontmatter(in interface{}) (string, error) {
+	data, err := yaml.Marshal(in)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("---\n%s---\n", data), nil
+}
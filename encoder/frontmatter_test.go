@@ -0,0 +1,131 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package encoder
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrontmatter(t *testing.T) {
+	fd := &FileDoc{
+		Name:        "Template",
+		Description: "Template is a nuclei template.\nIt contains requests.",
+		Weight:      2,
+	}
+
+	hugo, err := fd.HugoFrontmatter()
+	require.NoError(t, err)
+	assert.Equal(t, `---
+title: Template
+weight: 2
+description: Template is a nuclei template.
+---
+`, hugo)
+
+	docusaurus, err := fd.DocusaurusFrontmatter()
+	require.NoError(t, err)
+	assert.Equal(t, `---
+id: template
+title: Template
+sidebar_position: 2
+description: Template is a nuclei template.
+---
+`, docusaurus)
+
+	// pages are ordered by weight
+	docs := []*FileDoc{fd, {Name: "Workflow", Weight: 3}, {Name: "Global Options", Weight: 1}}
+
+	sidebar, err := DocusaurusSidebar("reference", "config", docs...)
+	require.NoError(t, err)
+	assert.Equal(t, `module.exports = {
+  "reference": [
+    "config/global-options",
+    "config/template",
+    "config/workflow"
+  ]
+};
+`, sidebar)
+
+	nav, err := MkDocsNav("Reference", "config", docs)
+	require.NoError(t, err)
+	assert.Equal(t, `nav:
+    - Reference:
+        - Global Options: config/global options.md
+        - Template: config/template.md
+        - Workflow: config/workflow.md
+`, string(nav))
+
+	nav, err = MkDocsNav("Reference", "config", docs, WithSlugFileNames())
+	require.NoError(t, err)
+	assert.Contains(t, string(nav), "- Global Options: config/global-options.md\n")
+
+	// pages with the same id would overwrite each other
+	_, err = DocusaurusSidebar("reference", "config", fd, &FileDoc{Name: "template"})
+	assert.EqualError(t, err, `file docs "Template" and "template" have the same name "template"`)
+
+	_, err = MkDocsNav("Reference", "config", []*FileDoc{{Name: "Global Options"}, {Name: "global-options"}}, WithSlugFileNames())
+	assert.EqualError(t, err, `file docs "Global Options" and "global-options" have the same name "global-options.md"`)
+}
+
+func TestFileDocID(t *testing.T) {
+	for name, id := range map[string]string{
+		"Template":          "template",
+		"Global Options":    "global-options",
+		"HTTP/Request v2.0": "http-request-v2-0",
+		"snake_case":        "snake_case",
+	} {
+		fd := &FileDoc{Name: name}
+
+		actual, err := fd.pageID()
+		require.NoError(t, err, name)
+		assert.Equal(t, id, actual, name)
+
+		fileName, err := fd.fileName(newRenderOptions(WithSlugFileNames()))
+		require.NoError(t, err, name)
+		assert.Equal(t, id+".md", fileName, name)
+	}
+
+	// file names keep the doc name unless slugs are enabled
+	fileName, err := (&FileDoc{Name: "Global Options"}).fileName(newRenderOptions())
+	require.NoError(t, err)
+	assert.Equal(t, "global options.md", fileName)
+
+	_, err = (&FileDoc{Name: "??"}).pageID()
+	assert.EqualError(t, err, `file doc "??" has an empty id`)
+
+	_, err = (&FileDoc{Name: "??"}).fileName(newRenderOptions(WithSlugFileNames()))
+	assert.EqualError(t, err, `file doc "??" has an empty file name`)
+}
+
+func TestWriteDocs(t *testing.T) {
+	dir := t.TempDir()
+	docs := []*FileDoc{{Name: "Global Options"}, {Name: "Template"}}
+
+	require.NoError(t, WriteDocs(dir, docs, (*FileDoc).HugoFrontmatter))
+
+	data, err := os.ReadFile(filepath.Join(dir, "global options.md"))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(data), "---\ntitle: Global Options\n"))
+
+	require.NoError(t, WriteDocs(dir, docs, nil, WithSlugFileNames()))
+	assert.FileExists(t, filepath.Join(dir, "global-options.md"))
+
+	// nothing is written if two docs share a file name
+	dir = t.TempDir()
+	err = WriteDocs(dir, append(docs, &FileDoc{Name: "template"}), nil)
+	assert.EqualError(t, err, `file docs "Template" and "template" have the same name "template.md"`)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	assert.EqualError(t, (&FileDoc{Name: ""}).Write(dir, ""), `file doc "" has an empty file name`)
+}
@@ -0,0 +1,269 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/projectdiscovery/yamldoc-go/encoder"
+)
+
+type Config struct {
+	Version string `yaml:"version"`
+	Jobs    []Job  `yaml:"jobs"`
+}
+
+type Job struct {
+	Name    string   `yaml:"name"`
+	Key     string   `yaml:"key"`
+	Options *Options `yaml:"options"`
+}
+
+type Options struct {
+	BulkSize int         `yaml:"bulk-size"`
+	Extra    interface{} `yaml:"extra"`
+}
+
+var configDoc, jobDoc, optionsDoc encoder.Doc
+
+func init() {
+	configDoc.Type = "Config"
+	configDoc.Fields = []encoder.Doc{
+		{Name: "version", Type: "string", Description: "Version of the config.", Values: []string{"v1", "v2"}},
+		{Name: "jobs", Type: "[]Job", Description: "Jobs to run."},
+	}
+
+	jobDoc.Type = "Job"
+	jobDoc.Fields = []encoder.Doc{
+		{Name: "name", Type: "string", Description: "Name of the job."},
+		{Name: "key", Type: "string", Description: "Key of the job.", Values: []string{"dns", "http"}},
+		{Name: "options", Type: "Options", Description: "Options of the job."},
+	}
+	jobDoc.Fields[0].AddExample("Name Example", "scan-${port}")
+
+	optionsDoc.Type = "Options"
+	optionsDoc.Fields = []encoder.Doc{
+		{Name: "bulk-size", Type: "int", Description: "Bulk size."},
+		{Name: "extra", Type: "interface{}", Description: "Extra options."},
+	}
+}
+
+func (Config) Doc() *encoder.Doc {
+	return &configDoc
+}
+
+func (Job) Doc() *encoder.Doc {
+	return &jobDoc
+}
+
+func (Options) Doc() *encoder.Doc {
+	return &optionsDoc
+}
+
+// client is a scripted LSP client talking to the server over pipes.
+type client struct {
+	t      *testing.T
+	in     io.Writer
+	out    *bufio.Reader
+	nextID int
+}
+
+func newClient(t *testing.T, server *Server) (*client, chan error) {
+	inReader, inWriter := io.Pipe()
+	outReader, outWriter := io.Pipe()
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- server.Serve(inReader, outWriter)
+		outWriter.Close() //nolint:errcheck
+	}()
+
+	return &client{t: t, in: inWriter, out: bufio.NewReader(outReader)}, done
+}
+
+func (c *client) notify(method string, params interface{}) {
+	require.NoError(c.t, writeMessage(c.in, map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  method,
+		"params":  params,
+	}))
+}
+
+func (c *client) call(method string, params interface{}, result interface{}) *responseError {
+	c.nextID++
+
+	require.NoError(c.t, writeMessage(c.in, map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      c.nextID,
+		"method":  method,
+		"params":  params,
+	}))
+
+	var resp struct {
+		ID     int             `json:"id"`
+		Result json.RawMessage `json:"result"`
+		Error  *responseError  `json:"error"`
+	}
+
+	c.read(&resp)
+	require.Equal(c.t, c.nextID, resp.ID)
+
+	if resp.Error == nil && result != nil {
+		require.NoError(c.t, json.Unmarshal(resp.Result, result))
+	}
+
+	return resp.Error
+}
+
+func (c *client) read(v interface{}) {
+	data, err := readMessage(c.out)
+	require.NoError(c.t, err)
+	require.NoError(c.t, json.Unmarshal(data, v))
+}
+
+func (c *client) diagnostics() publishDiagnosticsParams {
+	var msg struct {
+		Method string                   `json:"method"`
+		Params publishDiagnosticsParams `json:"params"`
+	}
+
+	c.read(&msg)
+	require.Equal(c.t, "textDocument/publishDiagnostics", msg.Method)
+
+	return msg.Params
+}
+
+func position(uri string, line, character int) map[string]interface{} {
+	return map[string]interface{}{
+		"textDocument": map[string]string{"uri": uri},
+		"position":     Position{Line: line, Character: character},
+	}
+}
+
+func labels(items []completionItem) []string {
+	res := []string{}
+	for _, item := range items {
+		res = append(res, item.Label)
+	}
+
+	return res
+}
+
+func TestServer(t *testing.T) {
+	server, err := NewDocumentedServer(Config{})
+	require.NoError(t, err)
+
+	c, done := newClient(t, server)
+
+	var initResult struct {
+		Capabilities map[string]interface{} `json:"capabilities"`
+	}
+	require.Nil(t, c.call("initialize", map[string]interface{}{}, &initResult))
+	assert.Equal(t, true, initResult.Capabilities["hoverProvider"])
+	c.notify("initialized", map[string]interface{}{})
+
+	const uri = "file:///config.yaml"
+
+	c.notify("textDocument/didOpen", map[string]interface{}{
+		"textDocument": textDocumentItem{
+			URI:        uri,
+			LanguageID: "yaml",
+			Text: `version: v3
+jobs:
+  - name: scan
+    key: ftp
+    unknown: 1
+    options:
+      bulk-size: many
+`,
+		},
+	})
+
+	diagnostics := c.diagnostics()
+	assert.Equal(t, uri, diagnostics.URI)
+	require.Len(t, diagnostics.Diagnostics, 4)
+	assert.Equal(t, `invalid value "v3" for version, expected one of: v1, v2`, diagnostics.Diagnostics[0].Message)
+	assert.Equal(t, Range{Start: Position{Line: 0, Character: 9}, End: Position{Line: 0, Character: 11}}, diagnostics.Diagnostics[0].Range)
+	assert.Equal(t, `invalid value "ftp" for key, expected one of: dns, http`, diagnostics.Diagnostics[1].Message)
+	assert.Equal(t, `unknown field "unknown" in Job`, diagnostics.Diagnostics[2].Message)
+	assert.Equal(t, Range{Start: Position{Line: 4, Character: 4}, End: Position{Line: 4, Character: 11}}, diagnostics.Diagnostics[2].Range)
+	assert.Equal(t, `expected int value`, diagnostics.Diagnostics[3].Message)
+
+	var h hover
+	require.Nil(t, c.call("textDocument/hover", position(uri, 3, 5), &h))
+	assert.Equal(t, "**key** `string`\n\nKey of the job.\n\nValid values: `dns`, `http`", h.Contents.Value)
+	assert.Equal(t, Range{Start: Position{Line: 3, Character: 4}, End: Position{Line: 3, Character: 7}}, *h.Range)
+
+	var list completionList
+	require.Nil(t, c.call("textDocument/completion", position(uri, 3, 9), &list))
+	assert.Equal(t, []string{"dns", "http"}, labels(list.Items))
+
+	c.notify("textDocument/didChange", map[string]interface{}{
+		"textDocument":   map[string]interface{}{"uri": uri, "version": 2},
+		"contentChanges": []map[string]string{{"text": "version: v1\njobs:\n  - name: scan\n    \n"}},
+	})
+	assert.Empty(t, c.diagnostics().Diagnostics)
+
+	require.Nil(t, c.call("textDocument/completion", position(uri, 3, 4), &list))
+	assert.Equal(t, []string{"name", "name (Name Example)", "key", "options"}, labels(list.Items))
+	assert.Equal(t, "name: scan-\\${port\\}", list.Items[1].InsertText)
+	assert.Equal(t, insertTextFormatSnippet, list.Items[1].InsertTextFormat)
+
+	require.Nil(t, c.call("textDocument/completion", position(uri, 1, 0), &list))
+	assert.Equal(t, []string{"version", "jobs"}, labels(list.Items))
+
+	rpcErr := c.call("workspace/symbol", map[string]interface{}{}, nil)
+	require.NotNil(t, rpcErr)
+	assert.Equal(t, codeMethodNotFound, rpcErr.Code)
+
+	c.notify("textDocument/didClose", map[string]interface{}{"textDocument": map[string]string{"uri": uri}})
+	assert.Empty(t, c.diagnostics().Diagnostics)
+
+	require.Nil(t, c.call("shutdown", nil, nil))
+	c.notify("exit", nil)
+	require.NoError(t, <-done)
+}
+
+func TestDiagnosticsSyntaxError(t *testing.T) {
+	server, err := NewServer("Config", &encoder.FileDoc{Structs: []*encoder.Doc{&configDoc, &jobDoc, &optionsDoc}})
+	require.NoError(t, err)
+
+	diagnostics := server.Diagnostics("version: v1\njobs: [\n")
+	require.Len(t, diagnostics, 1)
+	assert.Equal(t, SeverityError, diagnostics[0].Severity)
+	assert.Equal(t, 1, diagnostics[0].Range.Start.Line)
+
+	_, err = NewServer("Unknown")
+	assert.EqualError(t, err, `type "Unknown" is not documented`)
+}
+
+func TestExampleSnippetRedaction(t *testing.T) {
+	field := &encoder.Doc{Name: "token", Type: "string", Sensitive: true}
+	field.AddExample("Token Example", "secret-token")
+
+	snippet, err := exampleSnippet(field, field.Examples[0])
+	require.NoError(t, err)
+	assert.Equal(t, "token: <redacted>", snippet)
+}
+
+func TestDiagnosticsInterfaceField(t *testing.T) {
+	server, err := NewDocumentedServer(Config{})
+	require.NoError(t, err)
+
+	for _, value := range []string{"hello", "1.5", "true", "{a: 1}", "[1, 2]"} {
+		assert.Empty(t, server.Diagnostics("jobs:\n  - options:\n      extra: "+value+"\n"), value)
+	}
+
+	diagnostics := server.Diagnostics("jobs:\n  - options:\n      bulk-size: 1.5\n")
+	require.Len(t, diagnostics, 1)
+	assert.Equal(t, "expected int value", diagnostics[0].Message)
+}
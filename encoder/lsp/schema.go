@@ -0,0 +1,235 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package lsp
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/projectdiscovery/yamldoc-go/encoder"
+	yaml "gopkg.in/yaml.v3"
+)
+
+// schema resolves documentation of config keys starting from the root type.
+type schema struct {
+	docs *encoder.FileDoc
+	root string
+}
+
+func newSchema(root string, docs ...*encoder.FileDoc) (*schema, error) {
+	merged := &encoder.FileDoc{}
+	for _, fd := range docs {
+		merged.Structs = append(merged.Structs, fd.Structs...)
+	}
+
+	if merged.Lookup(root) == nil {
+		return nil, fmt.Errorf("type %q is not documented", root)
+	}
+
+	return &schema{
+		docs: merged,
+		root: root,
+	}, nil
+}
+
+// resolve returns the type and the documentation of the value at the key path,
+// the documentation is nil for map values.
+func (s *schema) resolve(path []string) (string, *encoder.Doc, bool) {
+	t := s.root
+
+	var field *encoder.Doc
+
+	for _, key := range path {
+		kind, elem := splitType(t)
+		if kind == encoder.FieldTypeMap {
+			t, field = elem, nil
+
+			continue
+		}
+
+		doc := s.docs.Lookup(elem)
+		if doc == nil {
+			return "", nil, false
+		}
+
+		if field = lookupField(doc, key); field == nil {
+			return "", nil, false
+		}
+
+		t = field.Type
+	}
+
+	return t, field, true
+}
+
+// structAt returns the documentation of the struct at the key path.
+func (s *schema) structAt(path []string) *encoder.Doc {
+	t, _, ok := s.resolve(path)
+	if !ok {
+		return nil
+	}
+
+	kind, elem := splitType(t)
+	if kind != encoder.FieldTypeValue {
+		return nil
+	}
+
+	return s.docs.Lookup(elem)
+}
+
+// validate reports unknown keys and invalid values in the node of type t.
+//
+//nolint:gocyclo
+func (s *schema) validate(node *yaml.Node, t string, diagnostics *[]Diagnostic) {
+	if node.Kind == yaml.AliasNode || node.ShortTag() == "!!null" {
+		return
+	}
+
+	t = strings.TrimPrefix(t, "*")
+	kind, elem := encoder.SplitFieldType(t)
+
+	switch kind {
+	case encoder.FieldTypeSlice:
+		if node.Kind != yaml.SequenceNode {
+			*diagnostics = append(*diagnostics, nodeDiagnostic(node, SeverityError, "expected a sequence of %s", elem))
+
+			return
+		}
+
+		for _, item := range node.Content {
+			s.validate(item, elem, diagnostics)
+		}
+	case encoder.FieldTypeMap:
+		if node.Kind != yaml.MappingNode {
+			*diagnostics = append(*diagnostics, nodeDiagnostic(node, SeverityError, "expected a mapping of %s", elem))
+
+			return
+		}
+
+		for i := 1; i < len(node.Content); i += 2 {
+			s.validate(node.Content[i], elem, diagnostics)
+		}
+	case encoder.FieldTypeValue:
+		doc := s.docs.Lookup(elem)
+		if doc == nil {
+			if message := checkScalar(node, elem); message != "" {
+				*diagnostics = append(*diagnostics, nodeDiagnostic(node, SeverityError, "%s", message))
+			}
+
+			return
+		}
+
+		if node.Kind != yaml.MappingNode {
+			*diagnostics = append(*diagnostics, nodeDiagnostic(node, SeverityError, "expected %s", elem))
+
+			return
+		}
+
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+
+			field := lookupField(doc, key.Value)
+			if field == nil {
+				*diagnostics = append(*diagnostics, nodeDiagnostic(key, SeverityError, "unknown field %q in %s", key.Value, elem))
+
+				continue
+			}
+
+			if values := allowedValues(field); len(values) > 0 && value.Kind == yaml.ScalarNode && !contains(values, value.Value) {
+				*diagnostics = append(*diagnostics, nodeDiagnostic(value, SeverityError, "invalid value %q for %s, expected one of: %s", value.Value, field.Name, strings.Join(values, ", ")))
+
+				continue
+			}
+
+			s.validate(value, field.Type, diagnostics)
+		}
+	}
+}
+
+// checkScalar checks scalar values of builtin types.
+func checkScalar(node *yaml.Node, t string) string {
+	var expected []string
+
+	switch t {
+	case "bool":
+		expected = []string{"!!bool"}
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+		expected = []string{"!!int"}
+	case "float32", "float64":
+		expected = []string{"!!int", "!!float"}
+	case "string":
+		if node.Kind != yaml.ScalarNode {
+			return "expected a string"
+		}
+
+		return ""
+	default:
+		return ""
+	}
+
+	if node.Kind != yaml.ScalarNode || !contains(expected, node.ShortTag()) {
+		return fmt.Sprintf("expected %s value", t)
+	}
+
+	return ""
+}
+
+// splitType strips slices and pointers from the type and returns the container
+// kind of the remaining type with its element type.
+func splitType(t string) (encoder.FieldTypeKind, string) {
+	for {
+		t = strings.TrimPrefix(t, "*")
+
+		kind, elem := encoder.SplitFieldType(t)
+		if kind != encoder.FieldTypeSlice {
+			return kind, strings.TrimPrefix(elem, "*")
+		}
+
+		t = elem
+	}
+}
+
+func lookupField(doc *encoder.Doc, name string) *encoder.Doc {
+	for i := range doc.Fields {
+		if doc.Fields[i].Name == name {
+			return &doc.Fields[i]
+		}
+	}
+
+	return nil
+}
+
+func allowedValues(field *encoder.Doc) []string {
+	values := make([]string, 0, len(field.Values)+len(field.EnumFields))
+	values = append(values, field.Values...)
+
+	return append(values, field.EnumFields...)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func nodeDiagnostic(node *yaml.Node, severity int, format string, args ...interface{}) Diagnostic {
+	start := Position{Line: node.Line - 1, Character: node.Column - 1}
+	end := start
+
+	if node.Kind == yaml.ScalarNode {
+		end.Character += len([]rune(node.Value))
+	}
+
+	return Diagnostic{
+		Range:    Range{Start: start, End: end},
+		Severity: severity,
+		Source:   source,
+		Message:  fmt.Sprintf(format, args...),
+	}
+}
@@ -0,0 +1,457 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package lsp implements a language server for YAML configs documented with yamldoc.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/projectdiscovery/yamldoc-go/encoder"
+	yaml "gopkg.in/yaml.v3"
+)
+
+const source = "yamldoc"
+
+// Server is a language server providing hover, completion and diagnostics
+// for configs of a documented root type.
+type Server struct {
+	schema *schema
+
+	documentsMutex sync.Mutex
+	documents      map[string]string
+
+	outMutex sync.Mutex
+	out      io.Writer
+}
+
+// NewServer initializes and returns a server for configs of the root type
+// documented in docs.
+func NewServer(rootType string, docs ...*encoder.FileDoc) (*Server, error) {
+	s, err := newSchema(rootType, docs...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Server{
+		schema:    s,
+		documents: map[string]string{},
+	}, nil
+}
+
+// NewDocumentedServer initializes and returns a server for configs of the root
+// type, docs of nested types are collected from the Documented struct fields.
+func NewDocumentedServer(root encoder.Documented) (*Server, error) {
+	doc := root.Doc()
+	if doc == nil {
+		return nil, fmt.Errorf("%T has no documentation", root)
+	}
+
+	return NewServer(doc.Type, encoder.CollectDocs(root))
+}
+
+// Serve handles messages read from r and writes responses to w until the
+// client sends the exit notification or closes the input.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	s.out = w
+	reader := bufio.NewReader(r)
+
+	for {
+		data, err := readMessage(reader)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		var req request
+		if err = json.Unmarshal(data, &req); err != nil {
+			if err = s.replyError(nil, codeParseError, err.Error()); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if req.Method == "exit" {
+			return nil
+		}
+
+		if err = s.handle(&req); err != nil {
+			return err
+		}
+	}
+}
+
+//nolint:gocyclo
+func (s *Server) handle(req *request) error {
+	switch req.Method {
+	case "initialize":
+		return s.reply(req.ID, map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				"textDocumentSync": textDocumentSyncFull,
+				"hoverProvider":    true,
+				"completionProvider": map[string]interface{}{
+					"triggerCharacters": []string{":", " "},
+				},
+			},
+			"serverInfo": map[string]string{
+				"name": source,
+			},
+		})
+	case "shutdown":
+		return s.reply(req.ID, nil)
+	case "textDocument/didOpen":
+		var params didOpenParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil
+		}
+
+		return s.update(params.TextDocument.URI, params.TextDocument.Text)
+	case "textDocument/didChange":
+		var params didChangeParams
+		if err := json.Unmarshal(req.Params, &params); err != nil || len(params.ContentChanges) == 0 {
+			return nil
+		}
+
+		return s.update(params.TextDocument.URI, params.ContentChanges[len(params.ContentChanges)-1].Text)
+	case "textDocument/didClose":
+		var params didCloseParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil
+		}
+
+		s.documentsMutex.Lock()
+		delete(s.documents, params.TextDocument.URI)
+		s.documentsMutex.Unlock()
+
+		return s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{
+			URI:         params.TextDocument.URI,
+			Diagnostics: []Diagnostic{},
+		})
+	case "textDocument/hover", "textDocument/completion":
+		var params textDocumentPositionParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return s.replyError(req.ID, codeInvalidParams, err.Error())
+		}
+
+		text := s.document(params.TextDocument.URI)
+
+		if req.Method == "textDocument/hover" {
+			if h := s.hoverAt(text, params.Position); h != nil {
+				return s.reply(req.ID, h)
+			}
+
+			return s.reply(req.ID, nil)
+		}
+
+		return s.reply(req.ID, completionList{Items: s.complete(text, params.Position)})
+	default:
+		// notifications which are not supported are ignored
+		if req.ID == nil {
+			return nil
+		}
+
+		return s.replyError(req.ID, codeMethodNotFound, fmt.Sprintf("method %q is not supported", req.Method))
+	}
+}
+
+func (s *Server) update(uri, text string) error {
+	s.documentsMutex.Lock()
+	s.documents[uri] = text
+	s.documentsMutex.Unlock()
+
+	return s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{
+		URI:         uri,
+		Diagnostics: s.Diagnostics(text),
+	})
+}
+
+func (s *Server) document(uri string) string {
+	s.documentsMutex.Lock()
+	defer s.documentsMutex.Unlock()
+
+	return s.documents[uri]
+}
+
+func (s *Server) reply(id *json.RawMessage, result interface{}) error {
+	return s.write(response{JSONRPC: "2.0", ID: id, Result: result})
+}
+
+func (s *Server) replyError(id *json.RawMessage, code int, message string) error {
+	return s.write(errorResponse{JSONRPC: "2.0", ID: id, Error: responseError{Code: code, Message: message}})
+}
+
+func (s *Server) notify(method string, params interface{}) error {
+	return s.write(notification{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+func (s *Server) write(msg interface{}) error {
+	s.outMutex.Lock()
+	defer s.outMutex.Unlock()
+
+	return writeMessage(s.out, msg)
+}
+
+var yamlErrorLineRe = regexp.MustCompile(`line (\d+):`)
+
+// Diagnostics returns syntax errors, unknown keys and invalid values found in the config.
+func (s *Server) Diagnostics(text string) []Diagnostic {
+	diagnostics := []Diagnostic{}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal([]byte(text), &node); err != nil {
+		line := 0
+		if m := yamlErrorLineRe.FindStringSubmatch(err.Error()); m != nil {
+			line, _ = strconv.Atoi(m[1])
+			line--
+		}
+
+		return append(diagnostics, Diagnostic{
+			Range: Range{
+				Start: Position{Line: line},
+				End:   Position{Line: line, Character: len([]rune(lineAt(text, line)))},
+			},
+			Severity: SeverityError,
+			Source:   source,
+			Message:  strings.TrimPrefix(err.Error(), "yaml: "),
+		})
+	}
+
+	if node.Kind == yaml.DocumentNode && len(node.Content) == 1 {
+		s.schema.validate(node.Content[0], s.schema.root, &diagnostics)
+	}
+
+	return diagnostics
+}
+
+// hoverAt returns documentation of the key at the position or nil.
+func (s *Server) hoverAt(text string, pos Position) *hover {
+	lines := strings.Split(text, "\n")
+	if pos.Line >= len(lines) {
+		return nil
+	}
+
+	l := parseLine(lines[pos.Line])
+	if !l.hasColon || pos.Character < l.keyStart || pos.Character > l.keyStart+len([]rune(l.key)) {
+		return nil
+	}
+
+	_, field, ok := s.schema.resolve(append(keyPath(lines, pos.Line, l.indent), l.key))
+	if !ok || field == nil {
+		return nil
+	}
+
+	return &hover{
+		Contents: markupContent{
+			Kind:  markupKindMarkdown,
+			Value: describe(field),
+		},
+		Range: &Range{
+			Start: Position{Line: pos.Line, Character: l.keyStart},
+			End:   Position{Line: pos.Line, Character: l.keyStart + len([]rune(l.key))},
+		},
+	}
+}
+
+func (s *Server) complete(text string, pos Position) []completionItem {
+	lines := strings.Split(text, "\n")
+	if pos.Line >= len(lines) {
+		return []completionItem{}
+	}
+
+	prefix := []rune(lines[pos.Line])
+	if pos.Character < len(prefix) {
+		prefix = prefix[:pos.Character]
+	}
+
+	l := parseLine(string(prefix))
+
+	if l.hasColon {
+		return s.completeValues(append(keyPath(lines, pos.Line, l.indent), l.key))
+	}
+
+	return s.completeKeys(keyPath(lines, pos.Line, l.indent))
+}
+
+func (s *Server) completeValues(path []string) []completionItem {
+	items := []completionItem{}
+
+	t, field, ok := s.schema.resolve(path)
+	if !ok || field == nil {
+		return items
+	}
+
+	values := allowedValues(field)
+	if len(values) == 0 && t == "bool" {
+		values = []string{"true", "false"}
+	}
+
+	for _, value := range values {
+		items = append(items, completionItem{
+			Label:  value,
+			Kind:   completionItemKindValue,
+			Detail: field.Type,
+		})
+	}
+
+	return items
+}
+
+func (s *Server) completeKeys(path []string) []completionItem {
+	items := []completionItem{}
+
+	doc := s.schema.structAt(path)
+	if doc == nil {
+		return items
+	}
+
+	for i := range doc.Fields {
+		field := &doc.Fields[i]
+		if field.Name == "" || field.Name == "-" {
+			continue
+		}
+
+		items = append(items, completionItem{
+			Label:            field.Name,
+			Kind:             completionItemKindProperty,
+			Detail:           field.Type,
+			Documentation:    &markupContent{Kind: markupKindMarkdown, Value: describe(field)},
+			InsertText:       field.Name + ": ",
+			InsertTextFormat: insertTextFormatPlainText,
+		})
+
+		for _, example := range field.Examples {
+			snippet, err := exampleSnippet(field, example)
+			if err != nil {
+				continue
+			}
+
+			label := field.Name + " example"
+			if example.GetName() != "" {
+				label = fmt.Sprintf("%s (%s)", field.Name, example.GetName())
+			}
+
+			items = append(items, completionItem{
+				Label:            label,
+				Kind:             completionItemKindSnippet,
+				Detail:           field.Type,
+				InsertText:       snippet,
+				InsertTextFormat: insertTextFormatSnippet,
+			})
+		}
+	}
+
+	return items
+}
+
+var snippetEscaper = strings.NewReplacer(`\`, `\\`, `$`, `\$`, `}`, `\}`)
+
+// exampleSnippet renders an example value as a snippet with the field key, sensitive values are redacted.
+func exampleSnippet(field *encoder.Doc, example *encoder.Example) (string, error) {
+	node, err := encoder.ExampleNode(field, example,
+		encoder.WithComments(encoder.CommentsDisabled), encoder.WithRedaction(encoder.RedactReplace))
+	if err != nil {
+		return "", err
+	}
+
+	data, err := encoder.NewEncoder(map[string]*yaml.Node{
+		field.Name: node,
+	}, encoder.WithComments(encoder.CommentsDisabled)).Encode()
+	if err != nil {
+		return "", err
+	}
+
+	return snippetEscaper.Replace(strings.TrimSuffix(string(data), "\n")), nil
+}
+
+// describe renders field documentation as markdown.
+func describe(field *encoder.Doc) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "**%s** `%s`", field.Name, field.Type)
+
+	if field.Description != "" {
+		fmt.Fprintf(&b, "\n\n%s", field.Description)
+	}
+
+	if values := allowedValues(field); len(values) > 0 {
+		fmt.Fprintf(&b, "\n\nValid values: `%s`", strings.Join(values, "`, `"))
+	}
+
+	if field.Note != "" {
+		fmt.Fprintf(&b, "\n\n> %s", field.Note)
+	}
+
+	return b.String()
+}
+
+// line is a config line split into indentation and key.
+type line struct {
+	indent   int
+	key      string
+	keyStart int
+	hasColon bool
+}
+
+// parseLine finds the key of a block mapping line, sequence item
+// markers are counted as indentation.
+func parseLine(text string) line {
+	runes := []rune(text)
+
+	i := 0
+	for i < len(runes) && (runes[i] == ' ' || runes[i] == '-' && (i+1 == len(runes) || runes[i+1] == ' ')) {
+		i++
+	}
+
+	l := line{indent: i, keyStart: i}
+
+	rest := string(runes[i:])
+	if strings.HasPrefix(rest, "#") {
+		return l
+	}
+
+	if c := strings.Index(rest+" ", ": "); c >= 0 {
+		l.key = strings.Trim(strings.TrimSpace(rest[:c]), `"'`)
+		l.hasColon = l.key != ""
+	}
+
+	return l
+}
+
+// keyPath returns keys of the mappings containing a line with the given indentation.
+func keyPath(lines []string, lineNo, indent int) []string {
+	path := []string{}
+
+	for i := lineNo - 1; i >= 0 && indent > 0; i-- {
+		l := parseLine(lines[i])
+		if !l.hasColon || l.key == "" || l.indent >= indent {
+			continue
+		}
+
+		path = append([]string{l.key}, path...)
+		indent = l.indent
+	}
+
+	return path
+}
+
+func lineAt(text string, line int) string {
+	lines := strings.Split(text, "\n")
+	if line < len(lines) {
+		return lines[line]
+	}
+
+	return ""
+}
@@ -0,0 +1,181 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package encoder
+
+import (
+	"fmt"
+	"strings"
+)
+
+// EncodeMan encodes file documentation as a roff man page.
+//
+// Man pages are rendered in section 5 (file formats) by default,
+// use WithManSection to change it.
+func (fd *FileDoc) EncodeMan(opts ...RenderOption) ([]byte, error) {
+	options := newRenderOptions(opts...)
+
+	var b strings.Builder
+
+	name := fd.id()
+	if name == "" {
+		name = fd.Name
+	}
+
+	fmt.Fprintf(&b, ".TH %s %d \"\" \"\" \"File Formats Manual\"\n", roffEscape(strings.ToUpper(name)), options.ManSection)
+	b.WriteString(".SH NAME\n")
+
+	if summary := fd.summary(); summary != "" {
+		fmt.Fprintf(&b, "%s \\- %s\n", roffEscape(name), roffEscape(summary))
+	} else {
+		fmt.Fprintf(&b, "%s\n", roffEscape(name))
+	}
+
+	if fd.Description != "" {
+		b.WriteString(".SH DESCRIPTION\n")
+		b.WriteString(roffText(fd.Description, ".PP"))
+	}
+
+	if len(fd.Structs) > 0 {
+		b.WriteString(".SH CONFIGURATION\n")
+	}
+
+	for _, s := range fd.Structs {
+		writeManStruct(&b, s, options.encoderOptions())
+	}
+
+	return []byte(b.String()), nil
+}
+
+//nolint:gocyclo
+func writeManStruct(b *strings.Builder, s *Doc, options *Options) {
+	fmt.Fprintf(b, ".SS %s\n", roffEscape(s.Type))
+
+	if s.Description != "" {
+		b.WriteString(".PP\n")
+		b.WriteString(roffText(s.Description, ".PP"))
+	}
+
+	if len(s.AppearsIn) > 0 {
+		b.WriteString(".PP\nAppears in:\n")
+
+		for _, appearance := range s.AppearsIn {
+			fmt.Fprintf(b, ".IP \\(bu 2\n\\fB%s.%s\\fR\n", roffEscape(appearance.TypeName), roffEscape(appearance.FieldName))
+		}
+	}
+
+	for _, example := range s.Examples {
+		b.WriteString(".PP\n")
+		writeManExample(b, renderYaml(example.GetValue(), "", example.GetName(), options))
+	}
+
+	if len(s.PartDefinitions) > 0 {
+		b.WriteString(".PP\nPart Definitions:\n")
+
+		for _, value := range s.PartDefinitions {
+			fmt.Fprintf(b, ".IP \\(bu 2\n\\fB%s\\fR \\- %s\n", roffEscape(value.Key), roffEscape(value.Value))
+		}
+	}
+
+	for _, field := range s.Fields {
+		fmt.Fprintf(b, ".TP\n\\fB%s\\fR \\fI%s\\fR", roffEscape(field.Name), roffEscape(field.Type))
+
+		if attributes := fieldAttributes(field); attributes != "" {
+			fmt.Fprintf(b, " (%s)", roffEscape(attributes))
+		}
+
+		b.WriteString("\n")
+
+		if field.Description != "" {
+			b.WriteString(roffText(field.Description, ".IP"))
+		}
+
+		if len(field.Values) > 0 {
+			fmt.Fprintf(b, ".IP\nValid values: %s\n", roffList(field.Values))
+		}
+
+		if len(field.EnumFields) > 0 {
+			fmt.Fprintf(b, ".IP\nEnum values: %s\n", roffList(field.EnumFields))
+		}
+
+		if field.Note != "" {
+			fmt.Fprintf(b, ".IP\nNote: %s\n", roffEscape(field.Note))
+		}
+
+		for _, example := range fieldExamples(&field, options) {
+			b.WriteString(".IP\n")
+			writeManExample(b, renderFieldYaml(&field, example, options))
+		}
+	}
+
+	if len(s.Values) > 0 {
+		fmt.Fprintf(b, ".PP\nValid values: %s\n", roffList(s.Values))
+	}
+}
+
+func writeManExample(b *strings.Builder, data string) {
+	b.WriteString(".nf\n")
+	b.WriteString(roffEscape(strings.TrimRight(data, "\n")))
+	b.WriteString("\n.fi\n")
+}
+
+// fieldAttributes returns a short summary of field requirements.
+func fieldAttributes(field Doc) string {
+	attributes := []string{}
+
+	if field.Required {
+		attributes = append(attributes, "required")
+	}
+
+	if field.Default != "" {
+		attributes = append(attributes, "default: "+field.Default)
+	}
+
+	return strings.Join(attributes, ", ")
+}
+
+func roffList(values []string) string {
+	items := make([]string, 0, len(values))
+
+	for _, value := range values {
+		items = append(items, "\\fB"+roffEscape(value)+"\\fR")
+	}
+
+	return strings.Join(items, ", ")
+}
+
+// roffText escapes the text and separates paragraphs with the
+// provided paragraph macro.
+func roffText(text, paragraph string) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+
+	var b strings.Builder
+
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			b.WriteString(paragraph + "\n")
+
+			continue
+		}
+
+		b.WriteString(roffEscape(line) + "\n")
+	}
+
+	return b.String()
+}
+
+// roffEscape escapes text to be safely used in roff documents.
+func roffEscape(text string) string {
+	text = strings.ReplaceAll(text, `\`, `\e`)
+	text = strings.ReplaceAll(text, "-", `\-`)
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'") {
+			lines[i] = `\&` + line
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,54 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package encoder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeMan(t *testing.T) {
+	fd := testFileDoc()
+	fd.Description = "Job configuration.\n.dot at line start"
+
+	data, err := fd.EncodeMan()
+	require.NoError(t, err)
+
+	out := string(data)
+
+	assert.Contains(t, out, ".TH JOB 5 \"\" \"\" \"File Formats Manual\"\n.SH NAME\njob \\- Job configuration.\n")
+	assert.Contains(t, out, ".SH DESCRIPTION\nJob configuration.\n\\&.dot at line start\n")
+	assert.Contains(t, out, ".TP\n\\fBname\\fR \\fIstring\\fR (required)\nName of the job\n")
+	assert.Contains(t, out, ".IP\n.nf\n# Name Example\nname: job\n.fi\n")
+	assert.Contains(t, out, ".IP\nValid values: \\fBdns\\fR, \\fBhttp\\fR\n")
+	assert.Contains(t, out, ".IP \\(bu 2\n\\fBJob.internal\\-options\\fR\n")
+
+	data, err = fd.EncodeMan(WithManSection(7))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), ".TH JOB 7 ")
+}
+
+func TestEncodeTerminal(t *testing.T) {
+	fd := testFileDoc()
+	fd.Description = "Job configuration with a long description which is wrapped at the width."
+
+	data, err := fd.EncodeTerminal(WithWidth(40))
+	require.NoError(t, err)
+
+	out := string(data)
+
+	assert.Contains(t, out, "  Job configuration with a long\n  description which is wrapped at the\n  width.\n")
+	assert.Contains(t, out, "  name  string (required)\n      Name of the job\n      with a | pipe\n      Example:\n        # Name Example\n        name: job\n")
+	assert.Contains(t, out, "  key  string (default: dns)\n      Valid values: dns, http\n")
+
+	assert.NotContains(t, out, "\x1b[")
+
+	data, err = fd.EncodeTerminal(WithColors(true))
+	require.NoError(t, err)
+
+	assert.Contains(t, string(data), "\x1b[90m# Name Example\x1b[0m\n        \x1b[36mname\x1b[0m: job\n")
+}
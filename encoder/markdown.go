@@ -12,24 +12,44 @@ import (
 	"regexp"
 	"strings"
 	"text/template"
-
-	yaml "gopkg.in/yaml.v3"
 )
 
 var markdownTemplate = `
 {{ define "fieldExamples" }}
 Examples:
 
-{{ range $example := .Examples }}
-{{ yaml $example.GetValue $.Name $example.GetName }}
+{{ range $example := visibleExamples . }}
+{{ fieldYaml $ $example }}
+{{ end }}
 {{ end }}
+
+{{ define "fieldsTable" }}
+| Field | Type | Required | Default | Description |
+|-------|------|----------|---------|-------------|
+{{ range $field := .Fields -}}
+| <a id="{{ fieldAnchor $.Type $field.Name }}"></a><code>{{ $field.Name }}</code> | <i>{{ encodeType $field.Type }}</i> | {{ if $field.Required }}Yes{{ else }}No{{ end }} | {{ if $field.Default }}<code>{{ tableCell $field.Default }}</code>{{ else }}-{{ end }} | {{ tableDescription $field }} |
+{{ end -}}
+{{ range $field := .Fields -}}
+{{ if visibleExamples $field }}
+<details>
+<summary><code>{{ $field.Name }}</code> examples</summary>
+
+{{ range $example := visibleExamples $field }}
+{{ fieldYaml $field $example }}
 {{ end }}
+</details>
+{{ end -}}
+{{ end -}}
+{{ end -}}
 
 {{ .Description }}
-{{- $anchors := .Anchors -}}
+{{- if tableOfContents }}
+
+{{ toc }}
+{{- end -}}
 {{- $tick := "` + "`" + `" -}}
 {{ range $struct := .Structs }}
-## {{ $struct.Type }}
+## <a id="{{ structAnchor $struct.Type }}"></a>{{ $struct.Type }}
 {{ if $struct.Description -}}
 {{ $struct.Description }}
 {{ end }}
@@ -55,14 +75,16 @@ Part Definitions:
 {{- end -}}
 {{ end }}
 
-{{ if $struct.Fields -}}
+{{ if and $struct.Fields tableLayout -}}
+{{ template "fieldsTable" $struct }}
+{{ else if $struct.Fields -}}
 
 <hr />
 
 {{ range $field := $struct.Fields -}}
 <div class="dd">
 
-<code>{{ $field.Name }}</code>  <i>{{ encodeType $field.Type }}</i>
+<a id="{{ fieldAnchor $struct.Type $field.Name }}"></a><code>{{ $field.Name }}</code>  <i>{{ encodeType $field.Type }}</i>
 
 </div>
 <div class="dt">
@@ -90,7 +112,7 @@ Enum Values:
 > {{ $field.Note }}
 {{ end -}}
 
-{{- if $field.Examples }}
+{{- if visibleExamples $field }}
 {{ template "fieldExamples" $field }}
 {{ end -}}
 
@@ -120,38 +142,70 @@ type FileDoc struct {
 	Description string
 	// Structs structs defined in the file.
 	Structs []*Doc
-	Anchors map[string]string
+	// Weight defines the position of the page in the site menus and navigation, lower first.
+	Weight int
+}
+
+// fileRender holds the anchors built for a single render of the file documentation,
+// so the FileDoc is never modified and can be rendered concurrently.
+type fileRender struct {
+	*FileDoc
 
-	t *template.Template
+	anchors      map[string]string
+	fieldAnchors map[string]string
 }
 
 // Encode encodes file documentation as MD file.
-func (fd *FileDoc) Encode() ([]byte, error) {
-	anchors := map[string]string{}
-	for _, t := range fd.Structs {
-		anchors[t.Type] = strings.ToLower(t.Type)
-	}
-	fd.Anchors = anchors
+func (fd *FileDoc) Encode(opts ...RenderOption) ([]byte, error) {
+	options := newRenderOptions(opts...)
+	encoderOptions := options.encoderOptions()
+
+	r := newFileRender(fd)
 
-	fd.t = template.Must(template.New("file_markdown.tpl").
+	t := template.Must(template.New("file_markdown.tpl").
 		Funcs(template.FuncMap{
-			"yaml":       encodeYaml,
-			"encodeType": fd.encodeType,
+			"yaml": func(in interface{}, name, description string) string {
+				return encodeYaml(in, name, description, encoderOptions)
+			},
+			"fieldYaml": func(field Doc, example *Example) string {
+				return fmt.Sprintf("```yaml\n%s```", renderFieldYaml(&field, example, encoderOptions))
+			},
+			"visibleExamples": func(field Doc) []*Example {
+				return fieldExamples(&field, encoderOptions)
+			},
+			"encodeType":       r.encodeType,
+			"tableCell":        tableCell,
+			"tableDescription": tableDescription,
+			"structAnchor":     r.structAnchor,
+			"fieldAnchor":      r.fieldAnchor,
+			"toc":              r.tableOfContents,
+			"tableLayout": func() bool {
+				return options.Layout == LayoutTable
+			},
+			"tableOfContents": func() bool {
+				return options.TableOfContents
+			},
 		}).
 		Parse(markdownTemplate))
 
 	buf := bytes.Buffer{}
 
-	if err := fd.t.Execute(&buf, fd); err != nil {
+	if err := t.Execute(&buf, fd); err != nil {
 		return nil, err
 	}
 
 	return buf.Bytes(), nil
 }
 
-// Write dumps documentation string to folder.
-func (fd *FileDoc) Write(path, frontmatter string) error {
-	data, err := fd.Encode()
+// Write dumps documentation string to folder, the file is named after the
+// lowercase doc name or its id with WithSlugFileNames.
+func (fd *FileDoc) Write(path, frontmatter string, opts ...RenderOption) error {
+	name, err := fd.fileName(newRenderOptions(opts...))
+	if err != nil {
+		return err
+	}
+
+	data, err := fd.Encode(opts...)
 	if err != nil {
 		return err
 	}
@@ -166,11 +220,13 @@ func (fd *FileDoc) Write(path, frontmatter string) error {
 		}
 	}
 
-	f, err := os.Create(filepath.Join(path, fmt.Sprintf("%s.%s", strings.ToLower(fd.Name), "md")))
+	f, err := os.Create(filepath.Join(path, name))
 	if err != nil {
 		return err
 	}
 
+	defer f.Close() //nolint:errcheck
+
 	if _, err := f.Write([]byte(frontmatter)); err != nil {
 		return err
 	}
@@ -179,21 +235,176 @@ func (fd *FileDoc) Write(path, frontmatter string) error {
 		return err
 	}
 
+	return f.Close()
+}
+
+// WriteDocs dumps the docs to folder with the frontmatter returned for each doc,
+// e.g. (*FileDoc).HugoFrontmatter. Docs with empty or duplicate file names are
+// rejected before writing anything.
+func WriteDocs(path string, docs []*FileDoc, frontmatter func(*FileDoc) (string, error), opts ...RenderOption) error {
+	if _, err := fileNames(docs, newRenderOptions(opts...)); err != nil {
+		return err
+	}
+
+	for _, fd := range docs {
+		var (
+			header string
+			err    error
+		)
+
+		if frontmatter != nil {
+			if header, err = frontmatter(fd); err != nil {
+				return fmt.Errorf("%s: %w", fd.Name, err)
+			}
+		}
+
+		if err = fd.Write(path, header, opts...); err != nil {
+			return fmt.Errorf("%s: %w", fd.Name, err)
+		}
+	}
+
 	return nil
 }
 
-var re = regexp.MustCompile(`[A-Za-z\.]+`)
+var (
+	re          = regexp.MustCompile(`[A-Za-z\.]+`)
+	anchorRe    = regexp.MustCompile(`[^a-z0-9_-]+`)
+	fieldNameRe = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// StructAnchors returns the anchors of the struct headings by struct types.
+func (fd *FileDoc) StructAnchors() map[string]string {
+	return newFileRender(fd).anchors
+}
+
+// newFileRender generates unique anchors for all structs and their fields.
+//
+// Struct anchors match the heading ids generated by markdown renderers,
+// with a numeric suffix added if two types produce the same anchor,
+// e.g. when they differ only by the package prefix.
+func newFileRender(fd *FileDoc) *fileRender {
+	r := &fileRender{
+		FileDoc:      fd,
+		anchors:      map[string]string{},
+		fieldAnchors: map[string]string{},
+	}
+
+	used := map[string]struct{}{}
+	unique := func(anchor string) string {
+		candidate := anchor
+		for i := 1; ; i++ {
+			if _, ok := used[candidate]; !ok {
+				break
+			}
+			candidate = fmt.Sprintf("%s-%d", anchor, i)
+		}
+		used[candidate] = struct{}{}
+
+		return candidate
+	}
+
+	for _, t := range fd.Structs {
+		if _, ok := r.anchors[t.Type]; ok {
+			continue
+		}
+		r.anchors[t.Type] = unique(anchorRe.ReplaceAllString(strings.ToLower(t.Type), ""))
+	}
+
+	for _, t := range fd.Structs {
+		for _, field := range t.Fields {
+			key := t.Type + "." + field.Name
+			if _, ok := r.fieldAnchors[key]; ok {
+				continue
+			}
+			name := strings.Trim(fieldNameRe.ReplaceAllString(strings.ToLower(field.Name), "-"), "-")
+			r.fieldAnchors[key] = unique(r.anchors[t.Type] + "-" + name)
+		}
+	}
+
+	return r
+}
+
+func (r *fileRender) structAnchor(typeName string) string {
+	return r.anchors[typeName]
+}
+
+func (r *fileRender) fieldAnchor(typeName, fieldName string) string {
+	return r.fieldAnchors[typeName+"."+fieldName]
+}
+
+// tableOfContents renders a list of links to all structs nested by the
+// types they appear in.
+func (r *fileRender) tableOfContents() string {
+	children := map[string][]*Doc{}
+	roots := []*Doc{}
+
+	for _, t := range r.Structs {
+		parents := 0
+		for _, appearance := range t.AppearsIn {
+			if _, ok := r.anchors[appearance.TypeName]; ok && appearance.TypeName != t.Type {
+				children[appearance.TypeName] = append(children[appearance.TypeName], t)
+				parents++
+			}
+		}
+		if parents == 0 {
+			roots = append(roots, t)
+		}
+	}
+
+	var (
+		buf     strings.Builder
+		visited = map[string]struct{}{}
+		visit   func(t *Doc, depth int)
+	)
+
+	visit = func(t *Doc, depth int) {
+		if _, ok := visited[t.Type]; ok {
+			return
+		}
+		visited[t.Type] = struct{}{}
+
+		fmt.Fprintf(&buf, "%s- [%s](#%s)\n", strings.Repeat("  ", depth), t.Type, r.anchors[t.Type])
+
+		for _, child := range children[t.Type] {
+			visit(child, depth+1)
+		}
+	}
+
+	for _, t := range roots {
+		visit(t, 0)
+	}
+
+	// structs which only reference each other have no root
+	for _, t := range r.Structs {
+		visit(t, 0)
+	}
+
+	return buf.String()
+}
 
-func (fd *FileDoc) encodeType(t string) string {
+func (r *fileRender) encodeType(t string) string {
+	return r.linkTypes(t, func(text, anchor string) string {
+		return formatLink(text, "#"+anchor)
+	})
+}
+
+// linkTypes replaces documented types in t with links built by link.
+func (r *fileRender) linkTypes(t string, link func(text, anchor string) string) string {
 	for _, s := range re.FindAllString(t, -1) {
-		if anchor, ok := fd.Anchors[s]; ok {
-			t = strings.ReplaceAll(t, s, formatLink(s, "#"+strings.ReplaceAll(anchor, ".", "")))
+		if anchor, ok := r.anchors[s]; ok {
+			t = strings.ReplaceAll(t, s, link(s, anchor))
 		}
 	}
 	return t
 }
 
-func encodeYaml(in interface{}, name string, description string) string {
+func encodeYaml(in interface{}, name string, description string, options *Options) string {
+	return fmt.Sprintf("```yaml\n%s```", renderYaml(in, name, description, options))
+}
+
+// renderYaml renders an example value as yaml with the description
+// prepended as a comment.
+func renderYaml(in interface{}, name string, description string, options *Options) string {
 	if name != "" {
 		in = map[string]interface{}{
 			name: in,
@@ -204,12 +415,12 @@ func encodeYaml(in interface{}, name string, description string) string {
 		yamlPrefix = fmt.Sprintf("# %s\n", description)
 	}
 
-	node, err := toYamlNode(in, CommentsAll)
+	node, err := toYamlNode(in, options)
 	if err != nil {
 		return fmt.Sprintf("yaml encoding failed %s", err)
 	}
 
-	data, err := yaml.Marshal(node)
+	data, err := marshalYaml(node, options)
 	if err != nil {
 		return fmt.Sprintf("yaml encoding failed %s", err)
 	}
@@ -219,7 +430,49 @@ func encodeYaml(in interface{}, name string, description string) string {
 		lines[i] = strings.TrimRight(line, " ")
 	}
 
-	return fmt.Sprintf("```yaml\n%s%s```", yamlPrefix, strings.Join(lines, "\n"))
+	return yamlPrefix + strings.Join(lines, "\n")
+}
+
+// tableCell escapes text to fit into a single markdown table cell.
+func tableCell(text string) string {
+	text = strings.TrimSpace(text)
+	text = strings.ReplaceAll(text, "|", "\\|")
+
+	return strings.ReplaceAll(text, "\n", "<br />")
+}
+
+// tableDescription renders field description, valid values and note
+// as a single markdown table cell.
+func tableDescription(field Doc) string {
+	parts := []string{}
+
+	if field.Description != "" {
+		parts = append(parts, tableCell(field.Description))
+	}
+
+	if len(field.Values) > 0 {
+		parts = append(parts, "Valid values: "+tableCodeList(field.Values))
+	}
+
+	if len(field.EnumFields) > 0 {
+		parts = append(parts, "Enum Values: "+tableCodeList(field.EnumFields))
+	}
+
+	if field.Note != "" {
+		parts = append(parts, "<i>Note: "+tableCell(field.Note)+"</i>")
+	}
+
+	return strings.Join(parts, "<br /><br />")
+}
+
+func tableCodeList(values []string) string {
+	items := make([]string, 0, len(values))
+
+	for _, value := range values {
+		items = append(items, "<code>"+tableCell(value)+"</code>")
+	}
+
+	return strings.Join(items, ", ")
 }
 
 func formatLink(text, link string) string {
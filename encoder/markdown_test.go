@@ -0,0 +1,111 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package encoder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testFileDoc() *FileDoc {
+	var jobDoc, optionsDoc Doc
+
+	jobDoc.Type = "Job"
+	jobDoc.Description = "Job is a single job."
+	jobDoc.Fields = make([]Doc, 3)
+	jobDoc.Fields[0].Name = "name"
+	jobDoc.Fields[0].Type = "string"
+	jobDoc.Fields[0].Description = "Name of the job\nwith a | pipe"
+	jobDoc.Fields[0].Required = true
+	jobDoc.Fields[0].AddExample("Name Example", "job")
+	jobDoc.Fields[1].Name = "key"
+	jobDoc.Fields[1].Type = "string"
+	jobDoc.Fields[1].Default = "dns"
+	jobDoc.Fields[1].Values = []string{"dns", "http"}
+	jobDoc.Fields[2].Name = "internal-options"
+	jobDoc.Fields[2].Type = "InternalOptions"
+
+	optionsDoc.Type = "InternalOptions"
+	optionsDoc.AppearsIn = []Appearance{{TypeName: "Job", FieldName: "internal-options"}}
+	optionsDoc.Fields = make([]Doc, 1)
+	optionsDoc.Fields[0].Name = "bulk-size"
+	optionsDoc.Fields[0].Type = "int"
+
+	return &FileDoc{
+		Name:    "Job",
+		Structs: []*Doc{&jobDoc, &optionsDoc},
+	}
+}
+
+func TestMarkdownTableLayout(t *testing.T) {
+	data, err := testFileDoc().Encode(WithLayout(LayoutTable))
+	require.NoError(t, err)
+
+	out := string(data)
+
+	assert.Contains(t, out, "| Field | Type | Required | Default | Description |")
+	assert.Contains(t, out, "| <a id=\"job-name\"></a><code>name</code> | <i>string</i> | Yes | - | Name of the job<br />with a \\| pipe |")
+	assert.Contains(t, out, "| <a id=\"job-key\"></a><code>key</code> | <i>string</i> | No | <code>dns</code> | Valid values: <code>dns</code>, <code>http</code> |")
+	assert.Contains(t, out, `| <a id="job-internal-options"></a><code>internal-options</code> | <i><a href="#internaloptions">InternalOptions</a></i> |`)
+	assert.Contains(t, out, "<details>\n<summary><code>name</code> examples</summary>")
+	assert.Contains(t, out, "```yaml\n# Name Example\nname: job\n```")
+	assert.NotContains(t, out, `<div class="dd">`)
+}
+
+func TestMarkdownDefinitionsLayout(t *testing.T) {
+	data, err := testFileDoc().Encode()
+	require.NoError(t, err)
+
+	assert.Contains(t, string(data), `<div class="dd">`)
+	assert.NotContains(t, string(data), "| Field |")
+}
+
+func TestMarkdownAnchors(t *testing.T) {
+	fd := testFileDoc()
+
+	data, err := fd.Encode(WithTableOfContents())
+	require.NoError(t, err)
+
+	out := string(data)
+
+	assert.Contains(t, out, "- [Job](#job)\n  - [InternalOptions](#internaloptions)\n")
+	assert.Contains(t, out, `## <a id="job"></a>Job`)
+	assert.Contains(t, out, `<a id="job-internal-options"></a><code>internal-options</code>`)
+	assert.Contains(t, out, `<a id="internaloptions-bulk-size"></a><code>bulk-size</code>`)
+}
+
+func TestMarkdownAnchorsCollision(t *testing.T) {
+	fd := &FileDoc{
+		Structs: []*Doc{
+			{Type: "model.Info", Fields: []Doc{{Name: "name", Type: "string"}}},
+			{Type: "modelInfo", Fields: []Doc{{Name: "name", Type: "string"}}},
+			{Type: "Holder", Fields: []Doc{{Name: "info", Type: "modelInfo"}}},
+		},
+	}
+
+	data, err := fd.Encode()
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"model.Info": "modelinfo",
+		"modelInfo":  "modelinfo-1",
+		"Holder":     "holder",
+	}, fd.StructAnchors())
+	assert.Equal(t, "modelinfo-1-name", newFileRender(fd).fieldAnchor("modelInfo", "name"))
+	assert.Contains(t, string(data), `<a href="#modelinfo-1">modelInfo</a>`)
+}
+
+func TestMarkdownEncoderOptions(t *testing.T) {
+	fd := testFileDoc()
+	fd.Structs[0].Fields[1].AddExample("", []string{"dns", "http"})
+
+	data, err := fd.Encode(WithEncoderOptions(WithIndent(2), WithCompactSequences(), WithQuoteStyle(QuoteSingle)))
+	require.NoError(t, err)
+
+	assert.Contains(t, string(data), "```yaml\n# Name Example\nname: 'job'\n```")
+	assert.Contains(t, string(data), "```yaml\nkey:\n- 'dns'\n- 'http'\n```")
+}
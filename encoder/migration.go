@@ -0,0 +1,614 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package encoder
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// MigrationStep transforms a yaml document tree in place.
+type MigrationStep func(root *yaml.Node) error
+
+// Migration upgrades a config from one version to the next one.
+type Migration struct {
+	From  string
+	To    string
+	Steps []MigrationStep
+}
+
+// Migrations is a registry of config migrations keyed by the version
+// they upgrade from.
+type Migrations struct {
+	versionKey string
+	migrations map[string]*Migration
+}
+
+// NewMigrations initializes and returns migrations reading the config
+// version from the versionKey of the root mapping.
+func NewMigrations(versionKey string) *Migrations {
+	return &Migrations{
+		versionKey: versionKey,
+		migrations: map[string]*Migration{},
+	}
+}
+
+// Register adds a migration from one version to another.
+func (m *Migrations) Register(from, to string, steps ...MigrationStep) {
+	m.migrations[from] = &Migration{
+		From:  from,
+		To:    to,
+		Steps: steps,
+	}
+}
+
+// Apply runs all migrations starting from the version of the document
+// and returns the list of applied migrations.
+//
+// The document is modified in place, comments of the moved and renamed
+// keys are preserved.
+func (m *Migrations) Apply(node *yaml.Node) ([]*Migration, error) {
+	root := documentRoot(node)
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("expected a mapping at the document root")
+	}
+
+	applied := []*Migration{}
+	visited := map[string]struct{}{}
+
+	for {
+		index := mappingIndex(root, m.versionKey)
+		if index < 0 {
+			return nil, fmt.Errorf("version key %q not found", m.versionKey)
+		}
+
+		version := root.Content[index+1].Value
+
+		migration, ok := m.migrations[version]
+		if !ok {
+			return applied, nil
+		}
+
+		if _, ok := visited[version]; ok {
+			return nil, fmt.Errorf("migration cycle detected at version %q", version)
+		}
+		visited[version] = struct{}{}
+
+		for i, step := range migration.Steps {
+			if err := step(root); err != nil {
+				return nil, fmt.Errorf("migration %s -> %s step %d: %w", migration.From, migration.To, i, err)
+			}
+		}
+
+		// the version key could have been moved by the steps
+		if index = mappingIndex(root, m.versionKey); index >= 0 {
+			root.Content[index+1].Value = migration.To
+		}
+
+		applied = append(applied, migration)
+	}
+}
+
+// MigrateFile upgrades a config file in place, re-decorating it with the
+// docs of the root type. The original file is kept with a ".bak" suffix,
+// the migration fails if the backup already exists.
+//
+// The indentation of the file is kept. The file is left untouched if no
+// migrations apply, files with multiple documents are not supported.
+func (m *Migrations) MigrateFile(path string, fd *FileDoc, rootType string) ([]*Migration, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	node, err := decodeDocument(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	// positions of the new nodes are unknown, so the format is read first
+	options := documentOptions(node)
+
+	applied, err := m.Apply(node)
+	if err != nil || len(applied) == 0 {
+		return applied, err
+	}
+
+	if fd != nil {
+		if err = fd.Decorate(node, rootType); err != nil {
+			return nil, err
+		}
+	}
+
+	migrated, err := marshalYaml(node, options)
+	if err != nil {
+		return nil, err
+	}
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = writeBackup(path+".bak", data, stat.Mode()); err != nil {
+		return nil, err
+	}
+
+	return applied, os.WriteFile(path, migrated, stat.Mode())
+}
+
+// decodeDocument decodes the only yaml document of the data.
+func decodeDocument(data []byte) (*yaml.Node, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+
+	node := &yaml.Node{}
+	if err := dec.Decode(node); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	var next yaml.Node
+	if err := dec.Decode(&next); !errors.Is(err, io.EOF) {
+		if err != nil {
+			return nil, err
+		}
+
+		return nil, errors.New("multiple yaml documents are not supported")
+	}
+
+	return node, nil
+}
+
+// documentOptions returns the encoder options keeping the indentation of the
+// decoded document.
+func documentOptions(node *yaml.Node) *Options {
+	mappingIndent, sequenceOffset := 0, -1
+
+	var walk func(node *yaml.Node)
+
+	walk = func(node *yaml.Node) {
+		if node.Kind == yaml.MappingNode {
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				key, value := node.Content[i], node.Content[i+1]
+
+				// only block values on the next lines are indented
+				if value.Style&yaml.FlowStyle != 0 || value.Line <= key.Line {
+					continue
+				}
+
+				offset := value.Column - key.Column
+
+				//nolint:exhaustive
+				switch value.Kind {
+				case yaml.MappingNode:
+					if mappingIndent == 0 && offset > 0 {
+						mappingIndent = offset
+					}
+				case yaml.SequenceNode:
+					if sequenceOffset < 0 && offset >= 0 {
+						sequenceOffset = offset
+					}
+				}
+			}
+		}
+
+		for _, child := range node.Content {
+			walk(child)
+		}
+	}
+
+	walk(node)
+
+	options := newOptions(WithIndent(mappingIndent))
+
+	switch {
+	case sequenceOffset < 0:
+	case options.Indent == 0 && sequenceOffset == 0:
+		options.Indent = 2
+	case options.Indent == 0:
+		options.Indent = sequenceOffset
+	}
+
+	// compact sequences are indented by two spaces less than mappings
+	options.CompactSequences = options.Indent >= 2 && sequenceOffset == options.Indent-2
+
+	return options
+}
+
+// writeBackup writes the data to a new file, existing files are never overwritten.
+func writeBackup(path string, data []byte, mode os.FileMode) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode)
+	if err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return fmt.Errorf("backup %s already exists", path)
+		}
+
+		return err
+	}
+
+	if _, err = f.Write(data); err != nil {
+		f.Close() //nolint:errcheck
+
+		return err
+	}
+
+	return f.Close()
+}
+
+// RenameKey renames the key at path, e.g. "providers.*.token" renames the
+// token key of every provider.
+func RenameKey(path, name string) MigrationStep {
+	return func(root *yaml.Node) error {
+		return walkPath(root, splitPath(path), func(mapping *yaml.Node, index int) error {
+			if index >= 0 {
+				if mappingIndex(mapping, name) >= 0 {
+					return fmt.Errorf("key %q already exists", name)
+				}
+
+				mapping.Content[index].Value = name
+			}
+
+			return nil
+		})
+	}
+}
+
+// MoveKey moves the key at path to another path keeping its comments,
+// missing parent mappings of the destination are created.
+func MoveKey(from, to string) MigrationStep {
+	return func(root *yaml.Node) error {
+		var key, value *yaml.Node
+
+		if err := walkPath(root, splitPath(from), func(mapping *yaml.Node, index int) error {
+			if index < 0 || key != nil {
+				return nil
+			}
+
+			key, value = mapping.Content[index], mapping.Content[index+1]
+			mapping.Content = append(mapping.Content[:index], mapping.Content[index+2:]...)
+
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		if key == nil {
+			return nil
+		}
+
+		segments := splitPath(to)
+		parent, err := ensureMapping(root, segments[:len(segments)-1])
+		if err != nil {
+			return err
+		}
+
+		name := segments[len(segments)-1]
+		if mappingIndex(parent, name) >= 0 {
+			return fmt.Errorf("key %q already exists", to)
+		}
+
+		key.Value = name
+		parent.Content = append(parent.Content, key, value)
+
+		return nil
+	}
+}
+
+// ChangeType replaces values at path with the result of convert.
+func ChangeType(path string, convert func(value *yaml.Node) (*yaml.Node, error)) MigrationStep {
+	return func(root *yaml.Node) error {
+		return walkPath(root, splitPath(path), func(mapping *yaml.Node, index int) error {
+			if index < 0 {
+				return nil
+			}
+
+			value, err := convert(mapping.Content[index+1])
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+
+			mapping.Content[index+1] = value
+
+			return nil
+		})
+	}
+}
+
+// SetDefault sets the key at path to value if it is not present.
+func SetDefault(path string, value interface{}) MigrationStep {
+	return func(root *yaml.Node) error {
+		segments := splitPath(path)
+
+		parent, err := ensureMapping(root, segments[:len(segments)-1])
+		if err != nil {
+			return err
+		}
+
+		name := segments[len(segments)-1]
+		if mappingIndex(parent, name) >= 0 {
+			return nil
+		}
+
+		key := &yaml.Node{}
+		if err = key.Encode(name); err != nil {
+			return err
+		}
+
+		node := &yaml.Node{}
+		if err = node.Encode(value); err != nil {
+			return err
+		}
+
+		parent.Content = append(parent.Content, key, node)
+
+		return nil
+	}
+}
+
+// ToScalarType returns a ChangeType converter which re-tags scalar values,
+// supported types are string, int, float and bool.
+func ToScalarType(kind string) func(value *yaml.Node) (*yaml.Node, error) {
+	return func(value *yaml.Node) (*yaml.Node, error) {
+		if value.Kind != yaml.ScalarNode {
+			return nil, fmt.Errorf("expected a scalar, got %s", nodeKindName(value.Kind))
+		}
+
+		res := *value
+		res.Style = 0
+
+		switch kind {
+		case "string":
+			res.Tag = "!!str"
+			res.Style = yaml.DoubleQuotedStyle
+		case "int":
+			if _, err := strconv.ParseInt(value.Value, 0, 64); err != nil {
+				return nil, err
+			}
+			res.Tag = "!!int"
+		case "float":
+			if _, err := strconv.ParseFloat(value.Value, 64); err != nil {
+				return nil, err
+			}
+			res.Tag = "!!float"
+		case "bool":
+			if _, err := strconv.ParseBool(value.Value); err != nil {
+				return nil, err
+			}
+			res.Tag = "!!bool"
+		default:
+			return nil, fmt.Errorf("unsupported type %q", kind)
+		}
+
+		return &res, nil
+	}
+}
+
+// ToSequence is a ChangeType converter wrapping a value into a sequence.
+func ToSequence(value *yaml.Node) (*yaml.Node, error) {
+	if value.Kind == yaml.SequenceNode {
+		return value, nil
+	}
+
+	item := *value
+	item.HeadComment, item.LineComment, item.FootComment = "", "", ""
+
+	return &yaml.Node{
+		Kind:        yaml.SequenceNode,
+		Tag:         "!!seq",
+		Content:     []*yaml.Node{&item},
+		HeadComment: value.HeadComment,
+		LineComment: value.LineComment,
+		FootComment: value.FootComment,
+	}, nil
+}
+
+// Decorate adds documentation comments of the root type to a yaml
+// document tree, keeping the comments which are already present.
+func (fd *FileDoc) Decorate(node *yaml.Node, rootType string) error {
+	doc := fd.Lookup(rootType)
+	if doc == nil {
+		return fmt.Errorf("type %q is not documented", rootType)
+	}
+
+	fd.decorateStruct(documentRoot(node), doc)
+
+	return nil
+}
+
+func (fd *FileDoc) decorateStruct(node *yaml.Node, doc *Doc) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+
+		var field *Doc
+		for j := range doc.Fields {
+			if doc.Fields[j].Name == key.Value {
+				field = &doc.Fields[j]
+			}
+		}
+
+		if field == nil {
+			continue
+		}
+
+		if key.HeadComment == "" && value.LineComment == "" {
+			if value.Kind == yaml.ScalarNode {
+				value.LineComment = field.Comments[LineComment]
+			} else {
+				key.HeadComment = field.Comments[LineComment]
+			}
+		}
+
+		fd.decorateValue(value, field.Type)
+	}
+}
+
+func (fd *FileDoc) decorateValue(node *yaml.Node, t string) {
+	kind, elem := SplitFieldType(t)
+
+	switch kind {
+	case FieldTypeSlice:
+		if node.Kind == yaml.SequenceNode {
+			for _, item := range node.Content {
+				fd.decorateValue(item, elem)
+			}
+		}
+	case FieldTypeMap:
+		if node.Kind == yaml.MappingNode {
+			for i := 1; i < len(node.Content); i += 2 {
+				fd.decorateValue(node.Content[i], elem)
+			}
+		}
+	case FieldTypeValue:
+		if doc := fd.Lookup(elem); doc != nil {
+			fd.decorateStruct(node, doc)
+		}
+	}
+}
+
+func documentRoot(node *yaml.Node) *yaml.Node {
+	if node.Kind == yaml.DocumentNode && len(node.Content) == 1 {
+		return node.Content[0]
+	}
+
+	return node
+}
+
+func splitPath(path string) []string {
+	return strings.Split(path, ".")
+}
+
+// mappingIndex returns the index of the key node in the mapping or -1.
+func mappingIndex(mapping *yaml.Node, key string) int {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// walkPath calls fn for every mapping matching the path parent with the
+// index of the last path segment key in it, or -1 if the key is missing.
+//
+// "*" segments match all mapping values or sequence items and numeric
+// segments match sequence items by index.
+func walkPath(node *yaml.Node, segments []string, fn func(mapping *yaml.Node, index int) error) error {
+	if len(segments) == 1 {
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+
+		return fn(node, mappingIndex(node, segments[0]))
+	}
+
+	for _, child := range pathChildren(node, segments[0]) {
+		if err := walkPath(child, segments[1:], fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func pathChildren(node *yaml.Node, segment string) []*yaml.Node {
+	switch node.Kind {
+	case yaml.MappingNode:
+		if segment == "*" {
+			children := []*yaml.Node{}
+			for i := 1; i < len(node.Content); i += 2 {
+				children = append(children, node.Content[i])
+			}
+
+			return children
+		}
+
+		if index := mappingIndex(node, segment); index >= 0 {
+			return []*yaml.Node{node.Content[index+1]}
+		}
+	case yaml.SequenceNode:
+		if segment == "*" {
+			return node.Content
+		}
+
+		if index, err := strconv.Atoi(segment); err == nil && index >= 0 && index < len(node.Content) {
+			return []*yaml.Node{node.Content[index]}
+		}
+	}
+
+	return nil
+}
+
+// ensureMapping returns the mapping at path creating missing mappings,
+// numeric segments select existing sequence items.
+func ensureMapping(root *yaml.Node, segments []string) (*yaml.Node, error) {
+	node := root
+
+	for _, segment := range segments {
+		if node.Kind == yaml.SequenceNode {
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(node.Content) {
+				return nil, fmt.Errorf("invalid sequence index %q", segment)
+			}
+
+			node = node.Content[index]
+
+			continue
+		}
+
+		if node.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("expected a mapping at %q, got %s", segment, nodeKindName(node.Kind))
+		}
+
+		index := mappingIndex(node, segment)
+		if index < 0 {
+			child := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: segment}, child)
+			node = child
+
+			continue
+		}
+
+		node = node.Content[index+1]
+
+		// empty values are replaced with a mapping
+		if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+			node.Kind, node.Tag, node.Value = yaml.MappingNode, "!!map", ""
+		}
+	}
+
+	if node.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("expected a mapping, got %s", nodeKindName(node.Kind))
+	}
+
+	return node, nil
+}
+
+func nodeKindName(kind yaml.Kind) string {
+	switch kind {
+	case yaml.DocumentNode:
+		return "document"
+	case yaml.SequenceNode:
+		return "sequence"
+	case yaml.MappingNode:
+		return "mapping"
+	case yaml.ScalarNode:
+		return "scalar"
+	case yaml.AliasNode:
+		return "alias"
+	default:
+		return "unknown node"
+	}
+}
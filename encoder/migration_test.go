@@ -0,0 +1,215 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package encoder
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v3"
+)
+
+func testMigrations() *Migrations {
+	m := NewMigrations("version")
+	m.Register("v1", "v2",
+		RenameKey("jobs.*.type", "key"),
+		MoveKey("bulk", "jobs.0.internal-options.bulk-size"),
+		ChangeType("jobs.*.internal-options.bulk-size", ToScalarType("int")),
+	)
+	m.Register("v2", "v3",
+		SetDefault("jobs.0.name", "default"),
+		ChangeType("tags", ToSequence),
+	)
+
+	return m
+}
+
+func migrationFileDoc() *FileDoc {
+	fd := testFileDoc()
+	fd.Structs = append(fd.Structs, &Doc{
+		Type: "Config",
+		Fields: []Doc{
+			{Name: "version", Type: "string", Comments: [3]string{LineComment: "Config version."}},
+			{Name: "jobs", Type: "[]Job", Comments: [3]string{LineComment: "List of jobs."}},
+		},
+	})
+	fd.Structs[0].Fields[0].Comments[LineComment] = "Name of the job."
+	fd.Structs[1].Fields[0].Comments[LineComment] = "Bulk size."
+
+	return fd
+}
+
+func TestMigrationsApply(t *testing.T) {
+	var node yaml.Node
+
+	require.NoError(t, yaml.Unmarshal([]byte(`version: v1
+# global bulk size
+bulk: "10"
+tags: dns
+jobs:
+  - type: dns # job type
+`), &node))
+
+	applied, err := testMigrations().Apply(&node)
+	require.NoError(t, err)
+	require.Len(t, applied, 2)
+	assert.Equal(t, "v3", applied[1].To)
+
+	data, err := yaml.Marshal(&node)
+	require.NoError(t, err)
+
+	assert.Equal(t, `version: v3
+tags:
+    - dns
+jobs:
+    - key: dns # job type
+      internal-options:
+        # global bulk size
+        bulk-size: 10
+      name: default
+`, string(data))
+}
+
+func TestMigrationsErrors(t *testing.T) {
+	var node yaml.Node
+
+	require.NoError(t, yaml.Unmarshal([]byte("version: v1\nname: a\nkey: b\n"), &node))
+
+	m := NewMigrations("version")
+	m.Register("v1", "v2", RenameKey("name", "key"))
+
+	_, err := m.Apply(&node)
+	assert.EqualError(t, err, `migration v1 -> v2 step 0: key "key" already exists`)
+
+	m = NewMigrations("version")
+	m.Register("v1", "v2")
+	m.Register("v2", "v1")
+
+	_, err = m.Apply(&node)
+	assert.EqualError(t, err, `migration cycle detected at version "v1"`)
+
+	_, err = NewMigrations("kind").Apply(&node)
+	assert.EqualError(t, err, `version key "kind" not found`)
+}
+
+func TestDecorate(t *testing.T) {
+	var node yaml.Node
+
+	require.NoError(t, yaml.Unmarshal([]byte(`version: v3
+jobs:
+  - name: a # my job
+    internal-options:
+      bulk-size: 10
+`), &node))
+
+	require.NoError(t, migrationFileDoc().Decorate(&node, "Config"))
+
+	data, err := yaml.Marshal(&node)
+	require.NoError(t, err)
+
+	assert.Equal(t, `version: v3 # Config version.
+# List of jobs.
+jobs:
+    - name: a # my job
+      internal-options:
+        bulk-size: 10 # Bulk size.
+`, string(data))
+
+	assert.EqualError(t, migrationFileDoc().Decorate(&node, "Unknown"), `type "Unknown" is not documented`)
+}
+
+func TestMigrateFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	original := "version: v2\ntags: dns\njobs:\n  - key: dns\n"
+
+	require.NoError(t, os.WriteFile(path, []byte(original), 0o644))
+
+	applied, err := testMigrations().MigrateFile(path, migrationFileDoc(), "Config")
+	require.NoError(t, err)
+	require.Len(t, applied, 1)
+
+	backup, err := os.ReadFile(path + ".bak")
+	require.NoError(t, err)
+	assert.Equal(t, original, string(backup))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, `version: v3 # Config version.
+tags:
+  - dns
+# List of jobs.
+jobs:
+  - key: dns
+    name: default # Name of the job.
+`, string(data))
+
+	applied, err = testMigrations().MigrateFile(path, migrationFileDoc(), "Config")
+	require.NoError(t, err)
+	assert.Empty(t, applied)
+}
+
+func TestMigrateFileFormat(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		original string
+		expected string
+	}{
+		{
+			name:     "compact",
+			original: "version: v2\ntags: dns\njobs:\n- key: dns\n",
+			expected: "version: v3\ntags:\n- dns\njobs:\n- key: dns\n  name: default\n",
+		},
+		{
+			name:     "compact four spaces",
+			original: "version: v2\ntags: dns\nmeta:\n    owner: ops\njobs:\n  - key: dns\n",
+			expected: "version: v3\ntags:\n  - dns\nmeta:\n    owner: ops\njobs:\n  - key: dns\n    name: default\n",
+		},
+		{
+			name:     "four spaces",
+			original: "version: v2\ntags: dns\njobs:\n    - key: dns\n",
+			expected: "version: v3\ntags:\n    - dns\njobs:\n    - key: dns\n      name: default\n",
+		},
+	} {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		require.NoError(t, os.WriteFile(path, []byte(test.original), 0o644))
+
+		_, err := testMigrations().MigrateFile(path, nil, "")
+		require.NoError(t, err, test.name)
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err, test.name)
+		assert.Equal(t, test.expected, string(data), test.name)
+	}
+}
+
+func TestMigrateFileErrors(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	original := "version: v2\ntags: dns\n"
+
+	// multiple documents
+	require.NoError(t, os.WriteFile(path, []byte(original+"---\nversion: v2\n"), 0o644))
+
+	_, err := testMigrations().MigrateFile(path, nil, "")
+	assert.EqualError(t, err, path+": multiple yaml documents are not supported")
+
+	// existing backup
+	require.NoError(t, os.WriteFile(path, []byte(original), 0o644))
+	require.NoError(t, os.WriteFile(path+".bak", []byte("previous"), 0o644))
+
+	_, err = testMigrations().MigrateFile(path, nil, "")
+	assert.EqualError(t, err, "backup "+path+".bak already exists")
+
+	backup, err := os.ReadFile(path + ".bak")
+	require.NoError(t, err)
+	assert.Equal(t, "previous", string(backup))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, original, string(data))
+}
@@ -4,6 +4,10 @@
 
 package encoder
 
+import (
+	yaml "gopkg.in/yaml.v3"
+)
+
 // CommentsFlags comments encoding flags type.
 type CommentsFlags int
 
@@ -18,13 +22,135 @@ const (
 	CommentsExamples CommentsFlags = 1 << iota
 	// CommentsDocs enables rendering each config field short docstring.
 	CommentsDocs
+	// CommentsValues appends the list of valid values to the field line comment.
+	CommentsValues
+	// CommentsFull renders the complete field description instead of its first line.
+	CommentsFull
 	// CommentsAll renders all comments.
 	CommentsAll = CommentsExamples | CommentsDocs
 )
 
+// Ordering defines the order of struct fields in the encoder output.
+type Ordering int
+
+const (
+	// OrderDeclaration keeps struct fields in declaration order.
+	OrderDeclaration Ordering = iota
+	// OrderAlphabetical sorts struct fields by their keys.
+	OrderAlphabetical
+	// OrderPriority sorts struct fields by the documented priority,
+	// fields with the same priority keep declaration order.
+	OrderPriority
+	// OrderCustom sorts struct fields and map keys using the KeyLess comparator.
+	OrderCustom
+)
+
+// QuoteStyle defines how string values are quoted in the encoder output.
+type QuoteStyle int
+
+const (
+	// QuoteAuto quotes strings only when required.
+	QuoteAuto QuoteStyle = iota
+	// QuoteDouble always uses double quotes for strings.
+	QuoteDouble
+	// QuoteSingle always uses single quotes for strings.
+	QuoteSingle
+)
+
+// MultilineStyle defines the style of multiline strings.
+type MultilineStyle int
+
+const (
+	// MultilineLiteral emits multiline strings in the literal block style (|).
+	MultilineLiteral MultilineStyle = iota
+	// MultilineFolded emits multiline strings in the folded block style (>).
+	MultilineFolded
+	// MultilineQuoted emits multiline strings double quoted with escaped line breaks.
+	MultilineQuoted
+)
+
+func (s MultilineStyle) style() yaml.Style {
+	switch s {
+	case MultilineFolded:
+		return yaml.FoldedStyle
+	case MultilineQuoted:
+		return yaml.DoubleQuotedStyle
+	case MultilineLiteral:
+	}
+
+	return yaml.LiteralStyle
+}
+
+// AnchorMode defines which repeated values are emitted as yaml anchors and aliases.
+type AnchorMode int
+
+const (
+	// AnchorsDisabled repeats values in full.
+	AnchorsDisabled AnchorMode = iota
+	// AnchorsPointers emits aliases for structs, maps and slices referenced by the same pointer.
+	AnchorsPointers
+	// AnchorsIdentical emits aliases for all identical mappings and sequences.
+	AnchorsIdentical
+)
+
+// Redaction defines how values of sensitive fields are encoded.
+type Redaction int
+
+const (
+	// RedactNone encodes sensitive values as is.
+	RedactNone Redaction = iota
+	// RedactReplace replaces sensitive scalar values with "<redacted>".
+	RedactReplace
+	// RedactHash replaces sensitive scalar values with a truncated HMAC-SHA256 hash keyed
+	// with Options.RedactionKey, so equal values can be matched without revealing them.
+	RedactHash
+	// RedactOmit leaves sensitive fields and their examples out.
+	RedactOmit
+)
+
 // Options defines encoder config.
 type Options struct {
 	Comments CommentsFlags
+	Ordering Ordering
+	// KeyLess compares keys with OrderCustom, non-string map keys are formatted with fmt.
+	KeyLess func(a, b string) bool
+	// Indent is the number of spaces used for indentation, inline examples
+	// are indented by the same width. Zero keeps the yaml.v3 defaults.
+	Indent int
+	// CompactSequences indents sequence items nested in mappings by two spaces
+	// less, so the "- " indicator is a part of the indentation.
+	CompactSequences bool
+	// LineWidth folds documentation comments longer than the width, zero disables folding.
+	LineWidth int
+	// QuoteStyle sets quoting of string values.
+	QuoteStyle QuoteStyle
+	// Multiline sets the style of multiline strings, block scalars can't be used
+	// for strings with trailing spaces and in flow collections and are double quoted.
+	Multiline MultilineStyle
+	// Anchors enables anchors and aliases for repeated values.
+	Anchors AnchorMode
+	// AnchorName returns the anchor name for the value first found at the
+	// dotted path, e.g. "jobs.0.credentials". Names are made unique by the encoder.
+	AnchorName func(path string) string
+	// Redaction hides values of fields marked as sensitive in the docs
+	// or with the `yamldoc:"secret"` tag.
+	Redaction Redaction
+	// RedactionKey is the key of values hashed with RedactHash. A random key is generated
+	// once per process if empty, so hashes can only be compared within the process.
+	RedactionKey []byte
+	// TagName is the secondary struct tag read for encoder options in addition
+	// to the `yaml` and `yamldoc` tags, DefaultTagName if empty.
+	TagName string
+	// PathComments override the comments flags for the matching values, later entries take precedence.
+	PathComments []PathComments
+	// ExampleDepth is the maximum depth of fields with rendered examples, top level fields have
+	// depth 1 and sequence items don't add a level. Zero renders examples at any depth.
+	ExampleDepth int
+	// FailOnExampleErrors fails the encoding with an ExampleError if a field example
+	// can't be rendered, otherwise the example is skipped.
+	FailOnExampleErrors bool
+	// Warnings collects the examples skipped by the encoder.
+	Warnings *Warnings
 }
 
 func newOptions(opts ...Option) *Options {
@@ -48,3 +174,220 @@ func WithComments(flags CommentsFlags) Option {
 		o.Comments = flags
 	}
 }
+
+// WithOrdering sets the order of struct fields in the encoder output.
+func WithOrdering(ordering Ordering) Option {
+	return func(o *Options) {
+		o.Ordering = ordering
+	}
+}
+
+// WithKeyComparator sorts struct fields and map keys using the less function.
+func WithKeyComparator(less func(a, b string) bool) Option {
+	return func(o *Options) {
+		o.Ordering = OrderCustom
+		o.KeyLess = less
+	}
+}
+
+// WithIndent sets the number of spaces used for indentation.
+func WithIndent(spaces int) Option {
+	return func(o *Options) {
+		o.Indent = spaces
+	}
+}
+
+// WithCompactSequences enables compact indentation of sequences nested in mappings.
+func WithCompactSequences() Option {
+	return func(o *Options) {
+		o.CompactSequences = true
+	}
+}
+
+// WithLineWidth folds documentation comments longer than the width.
+func WithLineWidth(width int) Option {
+	return func(o *Options) {
+		o.LineWidth = width
+	}
+}
+
+// WithQuoteStyle sets quoting of string values.
+func WithQuoteStyle(style QuoteStyle) Option {
+	return func(o *Options) {
+		o.QuoteStyle = style
+	}
+}
+
+// WithMultilineStyle sets the style of multiline strings.
+func WithMultilineStyle(style MultilineStyle) Option {
+	return func(o *Options) {
+		o.Multiline = style
+	}
+}
+
+// WithAnchors enables anchors and aliases for repeated values.
+func WithAnchors(mode AnchorMode) Option {
+	return func(o *Options) {
+		o.Anchors = mode
+	}
+}
+
+// WithAnchorNames sets the function naming anchors by the path of their definition.
+func WithAnchorNames(name func(path string) string) Option {
+	return func(o *Options) {
+		o.AnchorName = name
+	}
+}
+
+// WithRedaction hides values of sensitive fields in the output and examples.
+func WithRedaction(redaction Redaction) Option {
+	return func(o *Options) {
+		o.Redaction = redaction
+	}
+}
+
+// WithRedactionKey sets the key of values hashed with RedactHash, so hashes can be
+// compared across processes. The key must be kept secret.
+func WithRedactionKey(key []byte) Option {
+	return func(o *Options) {
+		o.RedactionKey = key
+	}
+}
+
+// WithTagName sets the secondary struct tag read for encoder options.
+func WithTagName(name string) Option {
+	return func(o *Options) {
+		o.TagName = name
+	}
+}
+
+// WithPathComments sets the comments flags for the values matching the selector and all nested values,
+// e.g. WithPathComments("providers.*", CommentsDocs).
+func WithPathComments(selector string, flags CommentsFlags) Option {
+	return func(o *Options) {
+		o.PathComments = append(o.PathComments, PathComments{
+			Selector: selector,
+			Comments: flags,
+		})
+	}
+}
+
+// WithExampleDepth limits the depth of fields with rendered examples.
+func WithExampleDepth(depth int) Option {
+	return func(o *Options) {
+		o.ExampleDepth = depth
+	}
+}
+
+// WithFailOnExampleErrors fails the encoding if a field example can't be rendered.
+func WithFailOnExampleErrors() Option {
+	return func(o *Options) {
+		o.FailOnExampleErrors = true
+	}
+}
+
+// WithWarnings collects the examples skipped by the encoder into the warnings.
+func WithWarnings(warnings *Warnings) Option {
+	return func(o *Options) {
+		o.Warnings = warnings
+	}
+}
+
+// Layout defines how struct fields are listed in the rendered documentation.
+type Layout int
+
+const (
+	// LayoutDefinitions renders each field as a definition block.
+	LayoutDefinitions Layout = iota
+	// LayoutTable renders fields as a table with examples in collapsible sections.
+	LayoutTable
+)
+
+// RenderOptions defines file documentation rendering config.
+type RenderOptions struct {
+	Layout          Layout
+	TableOfContents bool
+	// ManSection is the manual section of rendered man pages.
+	ManSection int
+	// Width is the maximum line width of the terminal output.
+	Width int
+	// Colors enables ANSI colors in the terminal output, disabled by default
+	// so the output can be piped or written to files.
+	Colors bool
+	// SlugFileNames names the files written by FileDoc.Write after the slugified
+	// doc name, e.g. global-options.md instead of "global options.md".
+	SlugFileNames bool
+	// Encoder options are used to render yaml examples.
+	Encoder []Option
+}
+
+func newRenderOptions(opts ...RenderOption) *RenderOptions {
+	res := &RenderOptions{
+		Layout:     LayoutDefinitions,
+		ManSection: 5,
+		Width:      80,
+	}
+
+	for _, o := range opts {
+		o(res)
+	}
+
+	return res
+}
+
+// encoderOptions returns the options used to render yaml examples.
+func (o *RenderOptions) encoderOptions() *Options {
+	return newOptions(o.Encoder...)
+}
+
+// RenderOption gives ability to alter file documentation rendering settings.
+type RenderOption func(*RenderOptions)
+
+// WithLayout sets the layout used to list struct fields.
+func WithLayout(layout Layout) RenderOption {
+	return func(o *RenderOptions) {
+		o.Layout = layout
+	}
+}
+
+// WithEncoderOptions sets the encoder options used to render yaml examples.
+func WithEncoderOptions(opts ...Option) RenderOption {
+	return func(o *RenderOptions) {
+		o.Encoder = append(o.Encoder, opts...)
+	}
+}
+
+// WithTableOfContents enables rendering a table of contents of all structs.
+func WithTableOfContents() RenderOption {
+	return func(o *RenderOptions) {
+		o.TableOfContents = true
+	}
+}
+
+// WithManSection sets the manual section of rendered man pages.
+func WithManSection(section int) RenderOption {
+	return func(o *RenderOptions) {
+		o.ManSection = section
+	}
+}
+
+// WithWidth sets the maximum line width of the terminal output.
+func WithWidth(width int) RenderOption {
+	return func(o *RenderOptions) {
+		o.Width = width
+	}
+}
+
+// WithColors enables or disables ANSI colors in the terminal output.
+func WithColors(enabled bool) RenderOption {
+	return func(o *RenderOptions) {
+		o.Colors = enabled
+	}
+}
+
+// WithSlugFileNames names written files after the slugified doc name.
+func WithSlugFileNames() RenderOption {
+	return func(o *RenderOptions) {
+		o.SlugFileNames = true
+	}
+}
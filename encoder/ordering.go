@@ -0,0 +1,119 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package encoder
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// sortFields reorders key/value pairs of the struct mapping node.
+func sortFields(node *yaml.Node, priorities map[*yaml.Node]int, options *Options) {
+	var less func(a, b *yaml.Node) bool
+
+	switch options.Ordering {
+	case OrderAlphabetical:
+		less = func(a, b *yaml.Node) bool {
+			return a.Value < b.Value
+		}
+	case OrderPriority:
+		less = func(a, b *yaml.Node) bool {
+			return priorities[a] > priorities[b]
+		}
+	case OrderCustom:
+		if options.KeyLess == nil {
+			return
+		}
+
+		less = func(a, b *yaml.Node) bool {
+			return options.KeyLess(a.Value, b.Value)
+		}
+	default:
+		return
+	}
+
+	sortPairs(node, less)
+}
+
+// sortPairs stable sorts key/value pairs of the mapping node by keys.
+func sortPairs(node *yaml.Node, less func(a, b *yaml.Node) bool) {
+	pairs := make([][2]*yaml.Node, 0, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		pairs = append(pairs, [2]*yaml.Node{node.Content[i], node.Content[i+1]})
+	}
+
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return less(pairs[i][0], pairs[j][0])
+	})
+
+	for i, pair := range pairs {
+		node.Content[2*i], node.Content[2*i+1] = pair[0], pair[1]
+	}
+}
+
+// sortMapKeys sorts map keys by their typed values, or using the custom comparator.
+func sortMapKeys(keys []reflect.Value, options *Options) {
+	if options.Ordering == OrderCustom && options.KeyLess != nil {
+		sort.SliceStable(keys, func(i, j int) bool {
+			return options.KeyLess(formatMapKey(keys[i]), formatMapKey(keys[j]))
+		})
+
+		return
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return mapKeyLess(keys[i], keys[j])
+	})
+}
+
+// mapKeyLess compares map keys of the same kind by value, keys of
+// different kinds (in maps with interface keys) are ordered by kind.
+//
+//nolint:exhaustive
+func mapKeyLess(a, b reflect.Value) bool {
+	for a.Kind() == reflect.Interface || a.Kind() == reflect.Ptr {
+		if a.IsNil() {
+			break
+		}
+		a = a.Elem()
+	}
+
+	for b.Kind() == reflect.Interface || b.Kind() == reflect.Ptr {
+		if b.IsNil() {
+			break
+		}
+		b = b.Elem()
+	}
+
+	if a.Kind() != b.Kind() {
+		return a.Kind() < b.Kind()
+	}
+
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() < b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return a.Uint() < b.Uint()
+	case reflect.Float32, reflect.Float64:
+		return a.Float() < b.Float()
+	case reflect.Bool:
+		return !a.Bool() && b.Bool()
+	case reflect.String:
+		return a.String() < b.String()
+	default:
+		return formatMapKey(a) < formatMapKey(b)
+	}
+}
+
+func formatMapKey(key reflect.Value) string {
+	if key.Kind() == reflect.String {
+		return key.String()
+	}
+
+	return fmt.Sprint(key.Interface())
+}
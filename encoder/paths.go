@@ -0,0 +1,65 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package encoder
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PathComments overrides the comments flags for the values matching the selector.
+type PathComments struct {
+	// Selector is a dotted path of keys, e.g. "providers.*.credentials".
+	// Sequence items are selected by index, "*" matches any single key or index.
+	Selector string
+	// Comments are the flags used for the selected values and all nested values.
+	Comments CommentsFlags
+}
+
+// matches checks if the selector matches the path or one of its parents.
+func (p *PathComments) matches(path []string) bool {
+	selector := strings.Split(p.Selector, ".")
+	if len(selector) > len(path) {
+		return false
+	}
+
+	for i, segment := range selector {
+		if segment != "*" && segment != path[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// childPath returns the path of the value stored under the key.
+func (s *encodeState) childPath(key interface{}) []string {
+	return append(s.path[:len(s.path):len(s.path)], fmt.Sprint(key))
+}
+
+// optionsAt returns the options used for comments of the value at the path,
+// depth is the number of keys in the path.
+func (s *encodeState) optionsAt(path []string, depth int) *Options {
+	if len(s.options.PathComments) == 0 && s.options.ExampleDepth == 0 {
+		return s.options
+	}
+
+	res := *s.options
+	// examples are rendered from the root, so the selectors don't apply inside them
+	res.PathComments = nil
+	res.ExampleDepth = 0
+
+	for i := range s.options.PathComments {
+		if s.options.PathComments[i].matches(path) {
+			res.Comments = s.options.PathComments[i].Comments
+		}
+	}
+
+	if s.options.ExampleDepth > 0 && depth > s.options.ExampleDepth {
+		res.Comments &^= CommentsExamples
+	}
+
+	return &res
+}
@@ -0,0 +1,93 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package encoder
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Team struct {
+	Lead    *Account   `yaml:"lead"`
+	Members []*Account `yaml:"members"`
+}
+
+func (t *Team) Doc() *Doc {
+	return &Doc{
+		Type: "Team",
+		Fields: []Doc{
+			{Name: "lead", Comments: [3]string{"", "Team lead."}},
+			{Name: "members", Comments: [3]string{"", "Team members."}},
+		},
+	}
+}
+
+func TestPathComments(t *testing.T) {
+	team := &Team{
+		Lead:    &Account{User: "lead"},
+		Members: []*Account{{User: "first"}, {User: "second"}},
+	}
+
+	for _, test := range []struct {
+		name     string
+		options  []Option
+		docs     int
+		examples int
+	}{
+		{
+			name:     "default",
+			docs:     3,
+			examples: 3,
+		},
+		{
+			name:     "depth",
+			options:  []Option{WithExampleDepth(1)},
+			docs:     3,
+			examples: 0,
+		},
+		{
+			name:     "depth ignores sequence items",
+			options:  []Option{WithExampleDepth(2)},
+			docs:     3,
+			examples: 3,
+		},
+		{
+			name:     "selector",
+			options:  []Option{WithPathComments("members.*", CommentsDocs)},
+			docs:     3,
+			examples: 1,
+		},
+		{
+			name:     "index",
+			options:  []Option{WithPathComments("members.*", CommentsDisabled), WithPathComments("members.1", CommentsAll)},
+			docs:     2,
+			examples: 2,
+		},
+		{
+			name:     "enable",
+			options:  []Option{WithComments(CommentsDisabled), WithPathComments("lead", CommentsAll)},
+			docs:     1,
+			examples: 1,
+		},
+	} {
+		data, err := NewEncoder(team, test.options...).Encode()
+		require.NoError(t, err)
+
+		assert.Equal(t, test.docs, strings.Count(string(data), "# User name."), test.name)
+		assert.Equal(t, test.examples, strings.Count(string(data), "api-key: example-key"), test.name)
+	}
+}
+
+func TestPathCommentsMatch(t *testing.T) {
+	rule := PathComments{Selector: "providers.*.credentials"}
+
+	assert.True(t, rule.matches([]string{"providers", "aws", "credentials"}))
+	assert.True(t, rule.matches([]string{"providers", "aws", "credentials", "token"}))
+	assert.False(t, rule.matches([]string{"providers", "aws"}))
+	assert.False(t, rule.matches([]string{"jobs", "aws", "credentials"}))
+}
@@ -0,0 +1,269 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package encoder
+
+import (
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// structPlan is the encoding layout of a struct type, which is built once per
+// type and tag name. Docs are read from the encoded values, as they can depend
+// on the receiver.
+type structPlan struct {
+	fields []fieldPlan
+	// err is the error of parsing the field tags
+	err error
+}
+
+// fieldPlan holds the parsed tag of a struct field.
+type fieldPlan struct {
+	index int
+	typ   reflect.Type
+	tag   fieldTag
+}
+
+type planKey struct {
+	typ     reflect.Type
+	tagName string
+}
+
+var (
+	structPlans sync.Map
+	elementDocs sync.Map
+)
+
+// getStructPlan returns the plan of the struct type t encoded from a value of the type in.
+func getStructPlan(in, t reflect.Type, options *Options) *structPlan {
+	key := planKey{typ: in, tagName: options.tagName()}
+
+	if plan, ok := structPlans.Load(key); ok {
+		return plan.(*structPlan) //nolint:forcetypeassert
+	}
+
+	plan := &structPlan{}
+
+	for i := 0; i < t.NumField(); i++ {
+		// skip unexported fields
+		if t.Field(i).PkgPath != "" {
+			continue
+		}
+
+		tag, err := parseFieldTag(t.Field(i), options)
+		if err != nil {
+			plan.err = err
+
+			break
+		}
+
+		field := fieldPlan{
+			index: i,
+			typ:   t.Field(i).Type,
+			tag:   tag,
+		}
+
+		if field.tag.name == "-" {
+			continue
+		}
+
+		plan.fields = append(plan.fields, field)
+	}
+
+	structPlans.Store(key, plan)
+
+	return plan
+}
+
+// docs returns the docs of the field value and the doc with the element type
+// examples rendered for empty fields.
+func (f *fieldPlan) docs(structDoc *Doc, value reflect.Value) (doc, exampleDoc *Doc) {
+	doc = fieldDoc(structDoc, f.index, value.Interface(), &f.tag)
+
+	return doc, withElementExamples(doc, f.typ)
+}
+
+// fieldDoc returns documentation data either from field, or from type.
+func fieldDoc(structDoc *Doc, i int, value interface{}, tag *fieldTag) *Doc {
+	var doc *Doc
+
+	if structDoc != nil {
+		doc = mergeDoc(getDoc(value), structDoc.Field(i))
+	} else {
+		doc = getDoc(value)
+	}
+
+	if tag.secret || tag.comment != "" || tag.style != "" {
+		var taggedDoc Doc
+		if doc != nil {
+			taggedDoc = *doc
+		}
+
+		taggedDoc.Sensitive = taggedDoc.Sensitive || tag.secret
+
+		if tag.comment != "" {
+			taggedDoc.Comments[LineComment] = tag.comment
+		}
+
+		if tag.style != "" {
+			taggedDoc.Style = tag.style
+		}
+
+		doc = &taggedDoc
+	}
+
+	return doc
+}
+
+// cachedElementDoc returns the cached documentation of the element type.
+func cachedElementDoc(t reflect.Type) *Doc {
+	if doc, ok := elementDocs.Load(t); ok {
+		return doc.(*Doc) //nolint:forcetypeassert
+	}
+
+	doc := elementDoc(t)
+	elementDocs.Store(t, doc)
+
+	return doc
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// encodeScalar encodes basic values directly, producing the same node as yaml.Node.Encode
+// without marshaling and parsing the value back. It returns false for other values.
+//
+//nolint:exhaustive
+func encodeScalar(node *yaml.Node, v reflect.Value) bool {
+	var tag, value string
+
+	// durations are encoded as strings by yaml.v3
+	if v.IsValid() && v.Type() == durationType {
+		return encodeString(node, time.Duration(v.Int()).String())
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return encodeString(node, v.String())
+	case reflect.Bool:
+		tag, value = "!!bool", strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		tag, value = "!!int", strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		tag, value = "!!int", strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		precision := 64
+		if v.Kind() == reflect.Float32 {
+			precision = 32
+		}
+
+		switch f := v.Float(); {
+		case math.IsInf(f, 1):
+			value = ".inf"
+		case math.IsInf(f, -1):
+			value = "-.inf"
+		case math.IsNaN(f):
+			value = ".nan"
+		default:
+			value = strconv.FormatFloat(f, 'g', -1, precision)
+		}
+
+		// whole numbers are resolved as integers
+		tag = plainTag(value)
+	default:
+		return false
+	}
+
+	node.Kind = yaml.ScalarNode
+	node.Tag = tag
+	node.Value = value
+
+	return true
+}
+
+// encodeString encodes single line strings with the style chosen by yaml.v3,
+// other strings are left to yaml.Node.Encode.
+func encodeString(node *yaml.Node, s string) bool {
+	if !isSimpleString(s) {
+		return false
+	}
+
+	node.Kind = yaml.ScalarNode
+	node.Tag = "!!str"
+	node.Value = s
+
+	if !canUsePlain(s) {
+		node.Style = yaml.DoubleQuotedStyle
+	}
+
+	return true
+}
+
+// isSimpleString checks if the string has no characters which make yaml.v3 emitter
+// switch to quoted styles on its own.
+func isSimpleString(s string) bool {
+	if s == "" {
+		return true
+	}
+
+	if s[0] == ' ' || s[len(s)-1] == ' ' {
+		return false
+	}
+
+	for i, r := range s {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '_', r == '.', r == '/':
+		case i > 0 && strings.ContainsRune(" -+=()@", r):
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// canUsePlain checks if the string is not resolved as a different type when unquoted.
+func canUsePlain(s string) bool {
+	return plainTag(s) == "!!str" && !isBase60Float(s) && !isOldBool(s)
+}
+
+// plainTag returns the tag resolved for the unquoted value.
+func plainTag(s string) string {
+	untagged := yaml.Node{Kind: yaml.ScalarNode, Value: s}
+
+	return untagged.ShortTag()
+}
+
+// base60float matches YAML 1.1 sexagesimal floats quoted by yaml.v3 for compatibility.
+var base60float = regexp.MustCompile(`^[-+]?[0-9][0-9_]*(?::[0-5]?[0-9])+(?:\.[0-9_]*)?$`)
+
+func isBase60Float(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	if c := s[0]; !(c == '+' || c == '-' || c >= '0' && c <= '9') || strings.IndexByte(s, ':') < 0 {
+		return false
+	}
+
+	return base60float.MatchString(s)
+}
+
+// isOldBool checks if the string is a YAML 1.1 bool quoted by yaml.v3 for compatibility.
+func isOldBool(s string) bool {
+	switch s {
+	case "y", "Y", "yes", "Yes", "YES", "on", "On", "ON",
+		"n", "N", "no", "No", "NO", "off", "Off", "OFF":
+		return true
+	default:
+		return false
+	}
+}
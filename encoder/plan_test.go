@@ -0,0 +1,118 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package encoder
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v3"
+)
+
+type Verbosity string
+
+type Tuning struct {
+	Level   Verbosity   `yaml:"level"`
+	Extra   interface{} `yaml:"extra,omitempty"`
+	Workers []*Worker   `yaml:"workers,omitempty"`
+	skipped string
+}
+
+func TestEncodeScalar(t *testing.T) {
+	values := []interface{}{
+		"", "plain", "multi\nline", "trailing\n", "  spaced", "true", "false", "null", "~",
+		"yes", "No", "off", "y", "12", "-1.5", "0x1F", "0o17", ".inf", "-.Inf", ".NaN",
+		"1:20", "190:20:30.15", "plain text 1.0", "path/to/file.yaml", "a-b_c", "x=(1+2)", "+1:2", "2001-12-14", "2001-12-14t21:59:43.10-05:00",
+		"- item", "key: value", "# comment", "a # b", "[list]", "{map}", "&anchor", "*alias",
+		"!tag", "|", ">", "'quoted'", "\"quoted\"", "@at", "`tick`", "%percent", "?", "? x",
+		":", "a:", "a:b", "<<", "=", "tab\there", "ünïcödé", "\x00", "bell\a",
+		Verbosity("debug"), Verbosity("on"),
+		true, false,
+		0, -42, int8(-8), int16(16), int32(-32), int64(math.MinInt64),
+		uint(7), uint8(8), uint16(16), uint32(32), uint64(math.MaxUint64), uintptr(9),
+		0.0, 1.5, -2.25, 1e21, 1e-7, float32(0.1), float32(3.4e38), 100.0,
+		math.Inf(1), math.Inf(-1), math.NaN(),
+		time.Duration(0), 90 * time.Second,
+	}
+
+	for _, value := range values {
+		var expected yaml.Node
+		require.NoError(t, expected.Encode(value))
+
+		var node yaml.Node
+		if !encodeScalar(&node, reflect.ValueOf(value)) {
+			// strings with special characters are left to yaml.v3
+			assert.IsType(t, "", value)
+
+			continue
+		}
+
+		assert.Equal(t, expected.Kind, node.Kind, "%#v", value)
+		assert.Equal(t, expected.Tag, node.Tag, "%#v", value)
+		assert.Equal(t, expected.Value, node.Value, "%#v", value)
+		assert.Equal(t, expected.Style, node.Style, "%#v", value)
+	}
+
+	var node yaml.Node
+	assert.True(t, encodeScalar(&node, reflect.ValueOf("plain text 1.0")))
+	assert.True(t, encodeScalar(&node, reflect.ValueOf("yes")))
+	assert.False(t, encodeScalar(&node, reflect.ValueOf("\xff")))
+	assert.False(t, encodeScalar(&node, reflect.ValueOf("key: value")))
+	assert.False(t, encodeScalar(&node, reflect.ValueOf([]int{1})))
+}
+
+type Labeled struct {
+	Name  string `yaml:"name"`
+	label string
+}
+
+func (l Labeled) Doc() *Doc {
+	return &Doc{
+		Fields: []Doc{{Name: "name", Comments: [3]string{"", l.label}}},
+	}
+}
+
+func TestStructPlan(t *testing.T) {
+	options := newOptions()
+	typ := reflect.TypeOf(&Tuning{})
+
+	plan := getStructPlan(typ, typ.Elem(), options)
+
+	assert.Same(t, plan, getStructPlan(typ, typ.Elem(), options))
+	require.Len(t, plan.fields, 3)
+	assert.Equal(t, "level", plan.fields[0].tag.name)
+	assert.Equal(t, 1, plan.fields[1].index)
+	assert.True(t, plan.fields[2].tag.omitEmpty)
+
+	// interface fields are documented by their value
+	doc, _ := plan.fields[1].docs(nil, reflect.ValueOf(&Tuning{Extra: Worker{}}).Elem().Field(1))
+	assert.Same(t, workerDoc, doc)
+
+	// slices without docs of their own get the examples of the element type
+	doc, _ = plan.fields[2].docs(nil, reflect.ValueOf(&Tuning{}).Elem().Field(2))
+	assert.Nil(t, doc)
+	assert.Same(t, cachedElementDoc(plan.fields[2].typ), workerDoc)
+
+	encoded, err := NewEncoder(&Tuning{Level: "debug", Extra: 1.5}, WithComments(CommentsAll)).Encode()
+	require.NoError(t, err)
+
+	expected, err := yaml.Marshal(&Tuning{Level: "debug", Extra: 1.5})
+	require.NoError(t, err)
+
+	assert.Equal(t, string(expected), string(encoded))
+}
+
+func TestStructPlanDocs(t *testing.T) {
+	// docs depending on the receiver are read from every encoded value
+	for _, label := range []string{"First label.", "Second label."} {
+		encoded, err := NewEncoder(Labeled{Name: "x", label: label}).Encode()
+		require.NoError(t, err)
+		assert.Equal(t, "name: x # "+label+"\n", string(encoded))
+	}
+}
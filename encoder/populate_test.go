@@ -0,0 +1,177 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package encoder
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Worker struct {
+	Name    string            `yaml:"name"`
+	Threads int               `yaml:"threads"`
+	Labels  map[string]string `yaml:"labels"`
+	Next    *Worker           `yaml:"next,omitempty"`
+}
+
+type Pool struct {
+	Workers []*Worker `yaml:"workers"`
+	Leader  *Worker   `yaml:"leader"`
+}
+
+var (
+	workerDoc = &Doc{
+		Fields: []Doc{
+			{Name: "name"},
+			{Name: "threads"},
+			{Name: "labels"},
+			{Name: "next"},
+		},
+	}
+	poolDoc = &Doc{
+		Fields: []Doc{
+			{Name: "workers"},
+			{Name: "leader"},
+		},
+	}
+	sharedWorker = &Worker{Name: "shared", Labels: map[string]string{"zone": "a"}}
+	nodeDoc      = &Doc{
+		Fields: []Doc{
+			{Name: "name"},
+			{Name: "child"},
+		},
+	}
+)
+
+type Node struct {
+	Name  string `yaml:"name"`
+	Child *Node  `yaml:"child,omitempty"`
+}
+
+func init() {
+	workerDoc.Fields[1].AddExample("", 4)
+	workerDoc.Fields[1].AddExample("", 8)
+	workerDoc.Fields[2].AddExample("", map[string]string{"zone": "b"})
+
+	poolDoc.Fields[1].AddExample("first", sharedWorker)
+	poolDoc.Fields[1].AddExample("second", sharedWorker)
+	poolDoc.Fields[0].AddExample("", []*Worker{sharedWorker})
+
+	nodeDoc.Fields[1].AddExample("", &Node{Name: "child"})
+}
+
+func (n Node) Doc() *Doc {
+	return nodeDoc
+}
+
+func (w Worker) Doc() *Doc {
+	return workerDoc
+}
+
+func (p Pool) Doc() *Doc {
+	return poolDoc
+}
+
+func TestExamplePopulated(t *testing.T) {
+	example := poolDoc.Fields[1].Examples[0]
+
+	first, ok := example.Populated(0).(*Worker)
+	require.True(t, ok)
+
+	second, ok := example.Populated(1).(*Worker)
+	require.True(t, ok)
+
+	assert.Equal(t, 4, first.Threads)
+	assert.Equal(t, 8, second.Threads)
+	assert.Equal(t, map[string]string{"zone": "b"}, first.Labels)
+
+	// copies are cached and never share state with the example value
+	assert.Same(t, first, example.Populated(0))
+	assert.NotSame(t, sharedWorker, first)
+	assert.Equal(t, &Worker{Name: "shared", Labels: map[string]string{"zone": "a"}}, sharedWorker)
+	assert.Same(t, sharedWorker, example.GetValue())
+
+	// cyclic values are copied with the cycle
+	cyclic := &Worker{Name: "cyclic"}
+	cyclic.Next = cyclic
+
+	copied, ok := deepCopy(reflect.ValueOf(cyclic)).Interface().(*Worker)
+	require.True(t, ok)
+	assert.NotSame(t, cyclic, copied)
+	assert.Same(t, copied, copied.Next)
+}
+
+func TestExamplePopulatedSelfReferential(t *testing.T) {
+	doc := &Doc{}
+	doc.AddExample("", &Node{Name: "root"})
+
+	populated, ok := doc.Examples[0].Populated(0).(*Node)
+	require.True(t, ok)
+	assert.Equal(t, &Node{Name: "root", Child: &Node{Name: "child"}}, populated)
+
+	cyclic := &Node{Name: "cyclic"}
+	cyclic.Child = cyclic
+	doc.AddExample("", cyclic)
+
+	populated, ok = doc.Examples[1].Populated(0).(*Node)
+	require.True(t, ok)
+	assert.Equal(t, "child", populated.Child.Name)
+}
+
+func TestConcurrentRendering(t *testing.T) {
+	fileDoc := &FileDoc{
+		Name:    "Pool",
+		Structs: []*Doc{poolDoc, workerDoc},
+	}
+
+	render := func() (string, string, string, error) {
+		encoded, err := NewEncoder(&Pool{}).Encode()
+		if err != nil {
+			return "", "", "", err
+		}
+
+		markdown, err := fileDoc.Encode()
+		if err != nil {
+			return "", "", "", err
+		}
+
+		example := encodeYaml(poolDoc.Fields[1].Examples[1].Populated(1), "leader", "", newOptions())
+
+		return string(encoded), string(markdown), example, nil
+	}
+
+	encoded, markdown, example, err := render()
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			// require can't stop the test from other goroutines
+			e, m, x, err := render()
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			assert.Equal(t, encoded, e)
+			assert.Equal(t, markdown, m)
+			assert.Equal(t, example, x)
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Contains(t, encoded, "#   threads: 4")
+	assert.Contains(t, example, "threads: 8")
+	assert.Equal(t, &Worker{Name: "shared", Labels: map[string]string{"zone": "a"}}, sharedWorker)
+}
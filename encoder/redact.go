@@ -0,0 +1,155 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package encoder
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// redactedValue replaces sensitive values with RedactReplace.
+const redactedValue = "<redacted>"
+
+// secretTag is the struct tag always read for the encoder options, e.g. `yamldoc:"secret"`.
+const secretTag = "yamldoc"
+
+var (
+	processKey     []byte
+	processKeyOnce sync.Once
+)
+
+// redactionKey returns the key of hashed values, a random key generated once per process if not set.
+func (o *Options) redactionKey() []byte {
+	if len(o.RedactionKey) > 0 {
+		return o.RedactionKey
+	}
+
+	processKeyOnce.Do(func() {
+		processKey = make([]byte, sha256.Size)
+
+		if _, err := rand.Read(processKey); err != nil {
+			panic(fmt.Sprintf("failed to generate redaction key: %s", err))
+		}
+	})
+
+	return processKey
+}
+
+// hashValue returns the truncated keyed hash of the value, unkeyed hashes of short
+// secrets could be reversed by brute force.
+func hashValue(value string, options *Options) string {
+	mac := hmac.New(sha256.New, options.redactionKey())
+	mac.Write([]byte(value))
+
+	return "hmac-sha256:" + hex.EncodeToString(mac.Sum(nil))[:16]
+}
+
+func (o *Options) redacts(doc *Doc) bool {
+	return doc != nil && doc.Sensitive && o.Redaction != RedactNone
+}
+
+// redactNode replaces all scalar values in the node, mapping keys are kept.
+func redactNode(node *yaml.Node, options *Options) {
+	//nolint:exhaustive
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			redactNode(child, options)
+		}
+	case yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			redactNode(node.Content[i], options)
+		}
+	case yaml.ScalarNode:
+		if node.ShortTag() == "!!null" {
+			return
+		}
+
+		switch options.Redaction {
+		case RedactHash:
+			node.Value = hashValue(node.Value, options)
+		case RedactReplace, RedactOmit, RedactNone:
+			node.Value = redactedValue
+		}
+
+		node.Tag = "!!str"
+		node.Style = 0
+
+		quoteScalar(node, options)
+	}
+}
+
+// redact hides the encoded value of a sensitive field.
+func (s *encodeState) redact(node *yaml.Node) {
+	redactNode(node, s.options)
+
+	// redacted values must not be aliased by the same values in other fields
+	var forget func(node *yaml.Node)
+
+	forget = func(node *yaml.Node) {
+		delete(s.origins, node)
+
+		for _, child := range node.Content {
+			forget(child)
+		}
+	}
+
+	forget(node)
+}
+
+// fieldExamples returns the examples of the field rendered in the documentation.
+func fieldExamples(field *Doc, options *Options) []*Example {
+	if options.redacts(field) && options.Redaction == RedactOmit {
+		return nil
+	}
+
+	return field.Examples
+}
+
+// ExampleNode encodes an example of the field with the options, the example is redacted if the
+// field is sensitive and sensitive nested fields are redacted as when encoding. It returns nil if
+// the examples of the field are left out with RedactOmit.
+func ExampleNode(field *Doc, example *Example, opts ...Option) (*yaml.Node, error) {
+	options := newOptions(opts...)
+
+	if options.redacts(field) && options.Redaction == RedactOmit {
+		return nil, nil
+	}
+
+	node, err := toYamlNode(example.GetValue(), options)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.redacts(field) {
+		redactNode(node, options)
+	}
+
+	return node, nil
+}
+
+// renderFieldYaml renders a field example as yaml with sensitive values redacted.
+func renderFieldYaml(field *Doc, example *Example, options *Options) string {
+	value := example.GetValue()
+
+	if options.redacts(field) {
+		node, err := toYamlNode(value, options)
+		if err != nil {
+			return fmt.Sprintf("yaml encoding failed %s", err)
+		}
+
+		redactNode(node, options)
+
+		value = node
+	}
+
+	return renderYaml(value, field.Name, example.GetName(), options)
+}
@@ -0,0 +1,150 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package encoder
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Account struct {
+	User     string            `yaml:"user"`
+	Password string            `yaml:"password,omitempty" yamldoc:"secret"`
+	Keys     map[string]string `yaml:"keys"`
+}
+
+func (a *Account) Doc() *Doc {
+	doc := &Doc{
+		Type: "Account",
+		Fields: []Doc{
+			{Name: "user", Type: "string", Comments: [3]string{"", "User name."}},
+			{Name: "password", Type: "string", Comments: [3]string{"", "User password."}},
+			{Name: "keys", Type: "map[string]string", Comments: [3]string{"", "API keys."}, Sensitive: true},
+		},
+	}
+
+	doc.Fields[2].AddExample("Keys Example", map[string]string{"api-key": "example-key"})
+
+	return doc
+}
+
+func TestRedaction(t *testing.T) {
+	account := &Account{
+		User:     "admin",
+		Password: "hunter2",
+		Keys:     map[string]string{"api-key": "k1", "access-token": "t1"},
+	}
+
+	t.Run("none", func(t *testing.T) {
+		data, err := NewEncoder(account, WithComments(CommentsDisabled)).Encode()
+		require.NoError(t, err)
+
+		assert.Contains(t, string(data), "hunter2")
+		assert.Contains(t, string(data), "k1")
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		data, err := NewEncoder(account, WithComments(CommentsDisabled), WithRedaction(RedactReplace)).Encode()
+		require.NoError(t, err)
+
+		assert.Equal(t, `user: admin
+password: <redacted>
+keys:
+    access-token: <redacted>
+    api-key: <redacted>
+`, string(data))
+	})
+
+	t.Run("hash", func(t *testing.T) {
+		data, err := NewEncoder(account, WithComments(CommentsDisabled), WithRedaction(RedactHash)).Encode()
+		require.NoError(t, err)
+
+		assert.Regexp(t, "password: hmac-sha256:[0-9a-f]{16}\n", string(data))
+		assert.NotContains(t, string(data), "hunter2")
+		assert.NotContains(t, string(data), "k1")
+
+		// the random process key is reused
+		again, err := NewEncoder(account, WithComments(CommentsDisabled), WithRedaction(RedactHash)).Encode()
+		require.NoError(t, err)
+		assert.Equal(t, string(data), string(again))
+
+		keyed, err := NewEncoder(account, WithComments(CommentsDisabled), WithRedaction(RedactHash),
+			WithRedactionKey([]byte("secret"))).Encode()
+		require.NoError(t, err)
+
+		mac := hmac.New(sha256.New, []byte("secret"))
+		mac.Write([]byte("hunter2"))
+
+		assert.Contains(t, string(keyed), "password: hmac-sha256:"+hex.EncodeToString(mac.Sum(nil))[:16]+"\n")
+		assert.NotEqual(t, string(data), string(keyed))
+	})
+
+	t.Run("omit", func(t *testing.T) {
+		data, err := NewEncoder(account, WithRedaction(RedactOmit)).Encode()
+		require.NoError(t, err)
+
+		assert.Equal(t, "user: admin # User name.\n", string(data))
+	})
+
+	t.Run("quoted", func(t *testing.T) {
+		data, err := NewEncoder(account, WithComments(CommentsDisabled), WithRedaction(RedactReplace), WithQuoteStyle(QuoteDouble)).Encode()
+		require.NoError(t, err)
+
+		assert.Contains(t, string(data), `password: "<redacted>"`)
+	})
+
+	t.Run("examples", func(t *testing.T) {
+		data, err := NewEncoder(&Account{User: "admin"}, WithRedaction(RedactReplace)).Encode()
+		require.NoError(t, err)
+
+		assert.NotContains(t, string(data), "example-key")
+		assert.Contains(t, string(data), "#   api-key: <redacted>")
+
+		data, err = NewEncoder(&Account{User: "admin"}, WithRedaction(RedactOmit)).Encode()
+		require.NoError(t, err)
+
+		assert.NotContains(t, string(data), "api-key")
+	})
+
+	t.Run("markdown", func(t *testing.T) {
+		doc := &FileDoc{
+			Name:    "Account",
+			Structs: []*Doc{(&Account{}).Doc()},
+		}
+
+		out, err := doc.Encode()
+		require.NoError(t, err)
+		assert.Contains(t, string(out), "example-key")
+
+		out, err = doc.Encode(WithEncoderOptions(WithRedaction(RedactReplace)))
+		require.NoError(t, err)
+		assert.NotContains(t, string(out), "example-key")
+		assert.Contains(t, string(out), "api-key: <redacted>")
+
+		out, err = doc.Encode(WithEncoderOptions(WithRedaction(RedactOmit)))
+		require.NoError(t, err)
+		assert.NotContains(t, string(out), "api-key")
+		assert.Equal(t, 0, strings.Count(string(out), "Examples:"))
+	})
+
+	t.Run("export", func(t *testing.T) {
+		doc := (&Account{}).Doc()
+		doc.AddExample("Admin", &Account{User: "admin", Password: "hunter2"})
+
+		data, err := ExportJSON(&FileDoc{Name: "Account", Structs: []*Doc{doc}})
+		require.NoError(t, err)
+
+		assert.NotContains(t, string(data), "example-key")
+		assert.NotContains(t, string(data), "hunter2")
+		assert.Contains(t, string(data), "api-key: \\u003credacted\\u003e")
+		assert.Contains(t, string(data), "password: \\u003credacted\\u003e")
+	})
+}
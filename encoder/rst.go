@@ -0,0 +1,148 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package encoder
+
+import (
+	"fmt"
+	"strings"
+)
+
+// EncodeRST encodes file documentation as a reStructuredText document.
+//
+// Types are cross-referenced with :ref: roles, so the document can be
+// included into a Sphinx project.
+func (fd *FileDoc) EncodeRST(opts ...RenderOption) ([]byte, error) {
+	options := newRenderOptions(opts...)
+
+	r := newFileRender(fd)
+
+	var b strings.Builder
+
+	writeRSTHeading(&b, fd.Name, "=")
+
+	if fd.Description != "" {
+		fmt.Fprintf(&b, "\n%s\n", strings.TrimSpace(fd.Description))
+	}
+
+	for _, s := range fd.Structs {
+		r.writeRSTStruct(&b, s, options.encoderOptions())
+	}
+
+	return []byte(b.String()), nil
+}
+
+//nolint:gocyclo
+func (r *fileRender) writeRSTStruct(b *strings.Builder, s *Doc, options *Options) {
+	fmt.Fprintf(b, "\n.. _%s:\n\n", r.structAnchor(s.Type))
+	writeRSTHeading(b, s.Type, "-")
+
+	if s.Description != "" {
+		fmt.Fprintf(b, "\n%s\n", strings.TrimSpace(s.Description))
+	}
+
+	if len(s.AppearsIn) > 0 {
+		b.WriteString("\nAppears in:\n\n")
+
+		for _, appearance := range s.AppearsIn {
+			// inline markup must be separated from the dot by escaped whitespace
+			fmt.Fprintf(b, "- %s\\ .\\ ``%s``\n", r.rstType(appearance.TypeName), appearance.FieldName)
+		}
+	}
+
+	for _, example := range s.Examples {
+		writeRSTExample(b, renderYaml(example.GetValue(), "", example.GetName(), options), 0)
+	}
+
+	if len(s.PartDefinitions) > 0 {
+		b.WriteString("\nPart Definitions:\n\n")
+
+		for _, value := range s.PartDefinitions {
+			fmt.Fprintf(b, "- ``%s`` - %s\n", value.Key, value.Value)
+		}
+	}
+
+	for _, field := range s.Fields {
+		fmt.Fprintf(b, "\n.. _%s:\n\n", r.fieldAnchor(s.Type, field.Name))
+		fmt.Fprintf(b, "``%s`` (%s", field.Name, r.rstType(field.Type))
+
+		if attributes := fieldAttributes(field); attributes != "" {
+			fmt.Fprintf(b, ", %s", attributes)
+		}
+
+		b.WriteString(")\n")
+
+		var body strings.Builder
+
+		if field.Description != "" {
+			body.WriteString("\n" + strings.TrimSpace(field.Description) + "\n")
+		}
+
+		writeRSTValues(&body, "Valid values:", field.Values, 0)
+		writeRSTValues(&body, "Enum Values:", field.EnumFields, 0)
+
+		if field.Note != "" {
+			body.WriteString("\n.. note:: " + field.Note + "\n")
+		}
+
+		for _, example := range fieldExamples(&field, options) {
+			writeRSTExample(&body, renderFieldYaml(&field, example, options), 0)
+		}
+
+		// definition list body is indented right below the term
+		if body.Len() > 0 {
+			b.WriteString(rstIndent(strings.TrimPrefix(body.String(), "\n"), 3))
+		}
+	}
+
+	if len(s.Values) > 0 {
+		writeRSTValues(b, s.Type+" Valid Values:", s.Values, 0)
+	}
+}
+
+func (r *fileRender) rstType(t string) string {
+	t = r.linkTypes(t, func(text, anchor string) string {
+		// escaped spaces allow roles next to other characters
+		return fmt.Sprintf("\\ :ref:`%s <%s>`\\ ", text, anchor)
+	})
+
+	return strings.TrimSuffix(strings.TrimPrefix(t, "\\ "), "\\ ")
+}
+
+func writeRSTHeading(b *strings.Builder, title, underline string) {
+	fmt.Fprintf(b, "%s\n%s\n", title, strings.Repeat(underline, len(title)))
+}
+
+func writeRSTValues(b *strings.Builder, title string, values []string, indent int) {
+	if len(values) == 0 {
+		return
+	}
+
+	lines := []string{title, ""}
+	for _, value := range values {
+		lines = append(lines, fmt.Sprintf("- ``%s``", value))
+	}
+
+	b.WriteString("\n")
+	b.WriteString(rstIndent(strings.Join(lines, "\n"), indent))
+}
+
+func writeRSTExample(b *strings.Builder, data string, indent int) {
+	b.WriteString("\n")
+	b.WriteString(rstIndent(".. code-block:: yaml\n\n"+rstIndent(strings.TrimRight(data, "\n"), 3), indent))
+}
+
+// rstIndent indents all non-empty lines of text.
+func rstIndent(text string, indent int) string {
+	prefix := strings.Repeat(" ", indent)
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = prefix + line
+		}
+	}
+
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -0,0 +1,30 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package encoder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeRST(t *testing.T) {
+	fd := testFileDoc()
+	fd.Structs[0].Fields[2].Type = "[]InternalOptions"
+	fd.Structs[0].Fields[2].Note = "internal use only"
+
+	data, err := fd.EncodeRST()
+	require.NoError(t, err)
+
+	out := string(data)
+
+	assert.Contains(t, out, ".. _job:\n\nJob\n---\n\nJob is a single job.\n")
+	assert.Contains(t, out, ".. _job-name:\n\n``name`` (string, required)\n   Name of the job\n   with a | pipe\n\n   .. code-block:: yaml\n\n      # Name Example\n      name: job\n")
+	assert.Contains(t, out, "``key`` (string, default: dns)\n   Valid values:\n\n   - ``dns``\n   - ``http``\n")
+	assert.Contains(t, out, "``internal-options`` ([]\\ :ref:`InternalOptions <internaloptions>`)\n   .. note:: internal use only\n")
+	assert.Contains(t, out, "Appears in:\n\n- :ref:`Job <job>`\\ .\\ ``internal-options``\n")
+	assert.NotContains(t, out, "<a href")
+}
@@ -0,0 +1,160 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package encoder
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// String styles which can be forced with the field tag options or the `style` docs annotation.
+const (
+	StyleLiteral = "literal"
+	StyleFolded  = "folded"
+	StyleQuoted  = "quoted"
+)
+
+var scalarStyles = map[string]yaml.Style{
+	StyleLiteral: yaml.LiteralStyle,
+	StyleFolded:  yaml.FoldedStyle,
+	StyleQuoted:  yaml.DoubleQuotedStyle,
+}
+
+// DefaultTagName is the secondary struct tag read for encoder options.
+const DefaultTagName = "talos"
+
+// fieldTag holds the struct tag options of a field.
+//
+// Options are read from the `yaml` tag, the secondary tag set with
+// WithTagName and the `yamldoc` tag:
+//
+//	omitempty     - skip the field if empty
+//	omitonlyifnil - skip the field only if nil, even when omitempty is set
+//	omitalways    - never encode the value, only its examples are rendered
+//	inline        - inline the struct or map fields into the parent
+//	flow          - use flow style for the value
+//	literal       - use literal block style (|) for strings
+//	folded        - use folded block style (>) for strings
+//	quoted        - use double quoted style for strings
+//	secret        - mark the field as sensitive, see WithRedaction
+//	comment=text  - override the documented line comment, must be the last option
+type fieldTag struct {
+	name string
+
+	omitEmpty     bool
+	omitOnlyIfNil bool
+	omitAlways    bool
+	inline        bool
+	flow          bool
+	secret        bool
+	style         string
+	comment       string
+}
+
+func (o *Options) tagName() string {
+	if o.TagName != "" {
+		return o.TagName
+	}
+
+	return DefaultTagName
+}
+
+// yamlFlags are the options accepted by yaml.v3 in the `yaml` tag.
+var yamlFlags = map[string]bool{
+	"omitempty": true,
+	"flow":      true,
+	"inline":    true,
+}
+
+// parseFieldTag parses the tags of the struct field.
+func parseFieldTag(field reflect.StructField, options *Options) (fieldTag, error) {
+	tag := field.Tag.Get("yaml")
+	parts := strings.SplitN(tag, ",", 2)
+
+	res := fieldTag{
+		name: parts[0],
+	}
+
+	if res.name == "" {
+		res.name = strings.ToLower(field.Name)
+	}
+
+	if len(parts) > 1 {
+		// yaml.v3 fails to decode the struct with other options
+		for _, flag := range strings.Split(parts[1], ",") {
+			if !yamlFlags[flag] {
+				return res, fmt.Errorf("unsupported flag %q in the yaml tag of field %s, set it in the %q or %q tag",
+					flag, field.Name, options.tagName(), secretTag)
+			}
+		}
+
+		res.parse(parts[1])
+	}
+
+	res.parse(field.Tag.Get(options.tagName()))
+
+	if options.tagName() != secretTag {
+		res.parse(field.Tag.Get(secretTag))
+	}
+
+	return res, nil
+}
+
+func (t *fieldTag) parse(tag string) {
+	for tag != "" {
+		if strings.HasPrefix(tag, "comment=") {
+			// comment takes the rest of the tag, so it can contain commas
+			t.comment = strings.TrimPrefix(tag, "comment=")
+
+			return
+		}
+
+		var part string
+
+		part, tag = tag, ""
+		if i := strings.Index(part, ","); i >= 0 {
+			part, tag = part[:i], part[i+1:]
+		}
+
+		switch part {
+		case "omitempty":
+			t.omitEmpty = true
+		case "omitonlyifnil":
+			t.omitOnlyIfNil = true
+		case "omitalways":
+			t.omitAlways = true
+		case "inline":
+			t.inline = true
+		case "flow":
+			t.flow = true
+		case "secret":
+			t.secret = true
+		case StyleLiteral, StyleFolded, StyleQuoted:
+			t.style = part
+		}
+	}
+}
+
+// applyStyle sets the string style forced by the field tag or docs on the value node.
+func applyStyle(node *yaml.Node, style string) {
+	if scalarStyles[style] == 0 {
+		return
+	}
+
+	//nolint:exhaustive
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if node.ShortTag() == "!!str" {
+			node.Style = scalarStyles[style]
+		}
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			applyStyle(item, style)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package encoder
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v3"
+)
+
+type Tagged struct {
+	Name     string   `yaml:"name" yamldoc:"comment=Name of the job, unique per project."`
+	Script   string   `yaml:"script" yamldoc:"literal"`
+	Summary  string   `yaml:"summary" yamldoc:"folded"`
+	Version  string   `yaml:"version" yamldoc:"quoted"`
+	Hosts    []string `yaml:"hosts" yamldoc:"quoted"`
+	Internal string   `yaml:"internal" yamldoc:"omitalways"`
+	Labels   []string `yaml:"labels" custom:"omitempty,flow"`
+	Legacy   string   `yaml:"legacy" talos:"omitempty"`
+}
+
+func (t *Tagged) Doc() *Doc {
+	doc := &Doc{
+		Type: "Tagged",
+		Fields: []Doc{
+			{Comments: [3]string{"", "Name of the job."}},
+			{},
+			{},
+			{},
+			{},
+			{Comments: [3]string{"", "Internal value."}},
+			{},
+			{},
+		},
+	}
+
+	doc.Fields[5].AddExample("", "value")
+
+	return doc
+}
+
+func TestParseFieldTag(t *testing.T) {
+	field, ok := reflect.TypeOf(Tagged{}).FieldByName("Name")
+	require.True(t, ok)
+
+	tag, err := parseFieldTag(field, newOptions())
+	require.NoError(t, err)
+	assert.Equal(t, "name", tag.name)
+	assert.Equal(t, "Name of the job, unique per project.", tag.comment)
+
+	field, ok = reflect.TypeOf(Tagged{}).FieldByName("Labels")
+	require.True(t, ok)
+
+	tag, err = parseFieldTag(field, newOptions())
+	require.NoError(t, err)
+	assert.False(t, tag.omitEmpty)
+
+	tag, err = parseFieldTag(field, newOptions(WithTagName("custom")))
+	require.NoError(t, err)
+	assert.True(t, tag.omitEmpty)
+	assert.True(t, tag.flow)
+}
+
+func TestTagOptions(t *testing.T) {
+	in := &Tagged{
+		Name:     "job",
+		Script:   "set -e\nrun\n",
+		Summary:  "short",
+		Version:  "1.0",
+		Hosts:    []string{"a"},
+		Internal: "hidden",
+		Labels:   []string{"x", "y"},
+	}
+
+	data, err := NewEncoder(in).Encode()
+	require.NoError(t, err)
+
+	assert.Equal(t, `name: job # Name of the job, unique per project.
+script: |
+    set -e
+    run
+summary: >-
+    short
+version: "1.0"
+hosts:
+    - "a"
+labels:
+    - x
+    - "y"
+
+# # Internal value.
+# internal: value
+`, string(data))
+
+	data, err = NewEncoder(in, WithTagName("custom")).Encode()
+	require.NoError(t, err)
+
+	assert.Contains(t, string(data), "labels: [x, \"y\"]\n")
+	assert.Contains(t, string(data), "legacy: \"\"\n")
+
+	var decoded Tagged
+	require.NoError(t, yaml.Unmarshal(data, &decoded))
+
+	in.Internal = ""
+	assert.Equal(t, in, &decoded)
+}
+
+func TestTagOptionsCommentsDisabled(t *testing.T) {
+	in := &Tagged{
+		Name:     "job",
+		Script:   "set -e\nrun\n",
+		Summary:  "short",
+		Version:  "1.0",
+		Hosts:    []string{"a"},
+		Internal: "hidden",
+		Labels:   []string{"x", "y"},
+	}
+
+	data, err := NewEncoder(in, WithComments(CommentsDisabled)).Encode()
+	require.NoError(t, err)
+
+	assert.Equal(t, `name: job
+script: |
+    set -e
+    run
+summary: >-
+    short
+version: "1.0"
+hosts:
+    - "a"
+labels:
+    - x
+    - "y"
+`, string(data))
+
+	data, err = NewEncoder(in, WithComments(CommentsDisabled), WithTagName("custom")).Encode()
+	require.NoError(t, err)
+
+	assert.Contains(t, string(data), "labels: [x, \"y\"]\n")
+	assert.NotContains(t, string(data), "hidden")
+}
+
+type YamlTagged struct {
+	Script string `yaml:"script,literal"`
+}
+
+func TestUnsupportedYamlFlag(t *testing.T) {
+	for _, flags := range []CommentsFlags{CommentsAll, CommentsDisabled} {
+		_, err := NewEncoder(&YamlTagged{Script: "run"}, WithComments(flags)).Encode()
+		require.Error(t, err)
+
+		var encodeErr *EncodeError
+		require.ErrorAs(t, err, &encodeErr)
+		assert.Contains(t, err.Error(), `unsupported flag "literal" in the yaml tag of field Script`)
+	}
+}
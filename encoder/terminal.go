@@ -0,0 +1,211 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package encoder
+
+import (
+	"regexp"
+	"strings"
+)
+
+const (
+	ansiReset   = "\x1b[0m"
+	ansiBold    = "\x1b[1m"
+	ansiField   = "\x1b[1;36m"
+	ansiType    = "\x1b[33m"
+	ansiKey     = "\x1b[36m"
+	ansiComment = "\x1b[90m"
+)
+
+var (
+	yamlKeyRe     = regexp.MustCompile(`^(\s*(?:- )?)([^#\s][^:#]*)(:(?:\s|$))`)
+	yamlCommentRe = regexp.MustCompile(`(^|\s)(#.*)$`)
+)
+
+type terminalWriter struct {
+	strings.Builder
+
+	options *RenderOptions
+}
+
+// EncodeTerminal encodes file documentation as plain text suitable for
+// terminal output, with descriptions wrapped to the configured width and
+// highlighted yaml examples.
+func (fd *FileDoc) EncodeTerminal(opts ...RenderOption) ([]byte, error) {
+	w := &terminalWriter{
+		options: newRenderOptions(opts...),
+	}
+
+	w.line(0, w.color(ansiBold, fd.Name))
+
+	if fd.Description != "" {
+		w.text(2, fd.Description)
+	}
+
+	for _, s := range fd.Structs {
+		w.WriteString("\n")
+		w.writeStruct(s)
+	}
+
+	return []byte(w.String()), nil
+}
+
+//nolint:gocyclo
+func (w *terminalWriter) writeStruct(s *Doc) {
+	w.line(0, w.color(ansiBold, s.Type))
+
+	if s.Description != "" {
+		w.text(2, s.Description)
+	}
+
+	if len(s.AppearsIn) > 0 {
+		appearances := make([]string, 0, len(s.AppearsIn))
+		for _, appearance := range s.AppearsIn {
+			appearances = append(appearances, appearance.TypeName+"."+appearance.FieldName)
+		}
+
+		w.WriteString("\n")
+		w.text(2, "Appears in: "+strings.Join(appearances, ", "))
+	}
+
+	for _, example := range s.Examples {
+		w.WriteString("\n")
+		w.yaml(2, renderYaml(example.GetValue(), "", example.GetName(), w.options.encoderOptions()))
+	}
+
+	if len(s.PartDefinitions) > 0 {
+		w.WriteString("\n")
+		w.line(2, "Part Definitions:")
+
+		for _, value := range s.PartDefinitions {
+			w.text(4, value.Key+" - "+value.Value)
+		}
+	}
+
+	for _, field := range s.Fields {
+		heading := w.color(ansiField, field.Name) + "  " + w.color(ansiType, field.Type)
+
+		if attributes := fieldAttributes(field); attributes != "" {
+			heading += " (" + attributes + ")"
+		}
+
+		w.WriteString("\n")
+		w.line(2, heading)
+
+		if field.Description != "" {
+			w.text(6, field.Description)
+		}
+
+		if len(field.Values) > 0 {
+			w.text(6, "Valid values: "+strings.Join(field.Values, ", "))
+		}
+
+		if len(field.EnumFields) > 0 {
+			w.text(6, "Enum values: "+strings.Join(field.EnumFields, ", "))
+		}
+
+		if field.Note != "" {
+			w.text(6, "Note: "+field.Note)
+		}
+
+		for _, example := range fieldExamples(&field, w.options.encoderOptions()) {
+			w.line(6, "Example:")
+			w.yaml(8, renderFieldYaml(&field, example, w.options.encoderOptions()))
+		}
+	}
+
+	if len(s.Values) > 0 {
+		w.WriteString("\n")
+		w.text(2, "Valid values: "+strings.Join(s.Values, ", "))
+	}
+}
+
+func (w *terminalWriter) color(code, text string) string {
+	if !w.options.Colors {
+		return text
+	}
+
+	return code + text + ansiReset
+}
+
+func (w *terminalWriter) line(indent int, text string) {
+	w.WriteString(strings.Repeat(" ", indent))
+	w.WriteString(text)
+	w.WriteString("\n")
+}
+
+// text writes the text word wrapped to the configured width.
+func (w *terminalWriter) text(indent int, text string) {
+	for _, line := range wrapText(strings.TrimSpace(text), w.options.Width-indent) {
+		if line == "" {
+			w.WriteString("\n")
+
+			continue
+		}
+
+		w.line(indent, line)
+	}
+}
+
+// yaml writes highlighted yaml, long lines are not wrapped to keep it valid.
+func (w *terminalWriter) yaml(indent int, data string) {
+	for _, line := range strings.Split(strings.TrimRight(data, "\n"), "\n") {
+		w.line(indent, w.highlightYaml(line))
+	}
+}
+
+func (w *terminalWriter) highlightYaml(line string) string {
+	if !w.options.Colors {
+		return line
+	}
+
+	if strings.HasPrefix(strings.TrimSpace(line), "#") {
+		return w.color(ansiComment, line)
+	}
+
+	var comment string
+	if loc := yamlCommentRe.FindStringSubmatchIndex(line); loc != nil {
+		comment = w.color(ansiComment, line[loc[4]:loc[5]])
+		line = line[:loc[4]]
+	}
+
+	line = yamlKeyRe.ReplaceAllString(line, "${1}"+ansiKey+"${2}"+ansiReset+"${3}")
+
+	return line + comment
+}
+
+// wrapText wraps the text to lines no longer than width, keeping
+// existing line breaks. Words longer than width are not split.
+func wrapText(text string, width int) []string {
+	if width < 20 {
+		width = 20
+	}
+
+	lines := []string{}
+
+	for _, paragraph := range strings.Split(text, "\n") {
+		words := strings.Fields(paragraph)
+		if len(words) == 0 {
+			lines = append(lines, "")
+
+			continue
+		}
+
+		current := words[0]
+		for _, word := range words[1:] {
+			if len(current)+1+len(word) > width {
+				lines = append(lines, current)
+				current = word
+
+				continue
+			}
+
+			current += " " + word
+		}
+
+		lines = append(lines, current)
+	}
+
+	return lines
+}
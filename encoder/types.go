@@ -0,0 +1,97 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package encoder
+
+import (
+	"reflect"
+	"strings"
+)
+
+// FieldTypeKind describes the container kind of a documented field type.
+type FieldTypeKind int
+
+const (
+	// FieldTypeValue is a plain value or struct.
+	FieldTypeValue FieldTypeKind = iota
+	// FieldTypeSlice is a slice of values.
+	FieldTypeSlice
+	// FieldTypeMap is a map of values.
+	FieldTypeMap
+)
+
+// SplitFieldType splits a documented field type into its outer container
+// kind and the element type, e.g. "[]Endpoint" into FieldTypeSlice and "Endpoint".
+func SplitFieldType(t string) (FieldTypeKind, string) {
+	switch {
+	case strings.HasPrefix(t, "[]"):
+		return FieldTypeSlice, t[2:]
+	case strings.HasPrefix(t, "map["):
+		depth := 0
+		for i, r := range t {
+			switch r {
+			case '[':
+				depth++
+			case ']':
+				depth--
+				if depth == 0 {
+					return FieldTypeMap, t[i+1:]
+				}
+			}
+		}
+	}
+
+	return FieldTypeValue, t
+}
+
+// Lookup returns the documentation of a struct by its type name.
+func (fd *FileDoc) Lookup(typeName string) *Doc {
+	for _, s := range fd.Structs {
+		if s.Type == typeName {
+			return s
+		}
+	}
+
+	return nil
+}
+
+// CollectDocs collects documentation of the root type and all documented
+// types reachable from its fields.
+func CollectDocs(root Documented) *FileDoc {
+	fd := &FileDoc{}
+	visited := map[reflect.Type]struct{}{}
+
+	var visit func(t reflect.Type)
+
+	visit = func(t reflect.Type) {
+		for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+			t = t.Elem()
+		}
+
+		if t.Kind() != reflect.Struct {
+			return
+		}
+
+		if _, ok := visited[t]; ok {
+			return
+		}
+		visited[t] = struct{}{}
+
+		if d, ok := reflect.New(t).Interface().(Documented); ok {
+			if doc := d.Doc(); doc != nil {
+				fd.Structs = append(fd.Structs, doc)
+			}
+		}
+
+		for i := 0; i < t.NumField(); i++ {
+			if f := t.Field(i); f.PkgPath == "" || f.Anonymous {
+				visit(f.Type)
+			}
+		}
+	}
+
+	visit(reflect.TypeOf(root))
+
+	return fd
+}
@@ -0,0 +1,445 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package wizard implements an interactive terminal wizard creating configs
+// from the documentation of their types.
+package wizard
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/projectdiscovery/yamldoc-go/encoder"
+	yaml "gopkg.in/yaml.v3"
+)
+
+// Wizard asks for config values reading answers line by line from the input.
+//
+// Empty answers accept the default value, the end of input accepts defaults
+// for all remaining questions, so answers can be scripted via stdin.
+type Wizard struct {
+	docs *encoder.FileDoc
+	root string
+
+	in  *bufio.Reader
+	out io.Writer
+	eof bool
+}
+
+// New initializes and returns a wizard for configs of the root type documented in docs.
+func New(in io.Reader, out io.Writer, rootType string, docs ...*encoder.FileDoc) (*Wizard, error) {
+	merged := &encoder.FileDoc{}
+	for _, fd := range docs {
+		merged.Structs = append(merged.Structs, fd.Structs...)
+	}
+
+	if merged.Lookup(rootType) == nil {
+		return nil, fmt.Errorf("type %q is not documented", rootType)
+	}
+
+	return &Wizard{
+		docs: merged,
+		root: rootType,
+		in:   bufio.NewReader(in),
+		out:  out,
+	}, nil
+}
+
+// Generate runs the wizard for the Documented target, decodes the answers into
+// it and encodes the result with the encoder options.
+func Generate(in io.Reader, out io.Writer, target encoder.Documented, opts ...encoder.Option) ([]byte, error) {
+	doc := target.Doc()
+	if doc == nil {
+		return nil, fmt.Errorf("%T has no documentation", target)
+	}
+
+	w, err := New(in, out, doc.Type, encoder.CollectDocs(target))
+	if err != nil {
+		return nil, err
+	}
+
+	node, err := w.Run()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := yaml.Marshal(node)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = encoder.DecodeExample(target, string(data)); err != nil {
+		return nil, err
+	}
+
+	return encoder.NewEncoder(target, opts...).Encode()
+}
+
+// Run asks for all fields of the root type and returns the resulting document.
+func (w *Wizard) Run() (*yaml.Node, error) {
+	node, err := w.structNode(w.docs.Lookup(w.root), "")
+	if err != nil {
+		return nil, err
+	}
+
+	return &yaml.Node{
+		Kind:    yaml.DocumentNode,
+		Content: []*yaml.Node{node},
+	}, nil
+}
+
+// Encode runs the wizard and returns the document decorated with the field docs.
+func (w *Wizard) Encode() ([]byte, error) {
+	node, err := w.Run()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = w.docs.Decorate(node, w.root); err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(node)
+}
+
+func (w *Wizard) structNode(doc *encoder.Doc, path string) (*yaml.Node, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+
+	for i := range doc.Fields {
+		field := &doc.Fields[i]
+		if field.Name == "" || field.Name == "-" {
+			continue
+		}
+
+		value, err := w.field(field, join(path, field.Name))
+		if err != nil {
+			return nil, err
+		}
+
+		if value != nil {
+			node.Content = append(node.Content, stringNode(field.Name), value)
+		}
+	}
+
+	return node, nil
+}
+
+// field asks for the field value and returns nil if the field is skipped.
+func (w *Wizard) field(field *encoder.Doc, path string) (*yaml.Node, error) {
+	w.printf("\n%s (%s)\n", path, field.Type)
+
+	if field.Description != "" {
+		w.printf("%s\n", indent(field.Description, "  "))
+	}
+
+	if w.isScalar(field.Type) {
+		return w.scalar(field, path)
+	}
+
+	// examples of sensitive fields are never offered
+	examples := []*encoder.Example{}
+	for _, example := range field.Examples {
+		if example.GetValue() != nil && !field.Sensitive {
+			examples = append(examples, example)
+		}
+	}
+
+	prompt := fmt.Sprintf("Configure %s? [y/N]: ", path)
+	if len(examples) > 0 {
+		for i, example := range examples {
+			w.printf("  %d) %s\n", i+1, exampleName(example, i))
+		}
+
+		prompt = fmt.Sprintf("Configure %s? [y/N or example number]: ", path)
+	}
+
+	for {
+		answer := strings.ToLower(w.ask(prompt))
+
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(examples) {
+			return encoder.ExampleNode(field, examples[n-1],
+				encoder.WithComments(encoder.CommentsDisabled), encoder.WithRedaction(encoder.RedactOmit))
+		}
+
+		switch answer {
+		case "", "n", "no":
+			return nil, nil
+		case "y", "yes":
+			return w.value(field.Type, path)
+		}
+
+		w.printf("invalid answer %q\n", answer)
+	}
+}
+
+// value asks for a value of a compound type.
+func (w *Wizard) value(t, path string) (*yaml.Node, error) {
+	kind, elem := encoder.SplitFieldType(strings.TrimPrefix(t, "*"))
+
+	switch kind {
+	case encoder.FieldTypeSlice:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+
+		for i := 0; ; i++ {
+			if !w.confirm(fmt.Sprintf("Add an item to %s? [y/N]: ", path)) {
+				return node, nil
+			}
+
+			item, err := w.item(elem, fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+
+			node.Content = append(node.Content, item)
+		}
+	case encoder.FieldTypeMap:
+		node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+
+		for {
+			key := w.ask(fmt.Sprintf("%s key (empty to finish): ", path))
+			if key == "" {
+				return node, nil
+			}
+
+			value, err := w.item(elem, join(path, key))
+			if err != nil {
+				return nil, err
+			}
+
+			node.Content = append(node.Content, stringNode(key), value)
+		}
+	default:
+		doc := w.docs.Lookup(strings.TrimPrefix(elem, "*"))
+		if doc == nil {
+			return w.scalar(&encoder.Doc{Type: elem}, path)
+		}
+
+		return w.structNode(doc, path)
+	}
+}
+
+// item asks for a slice item or map value.
+func (w *Wizard) item(t, path string) (*yaml.Node, error) {
+	if !w.isScalar(t) {
+		return w.value(t, path)
+	}
+
+	node, err := w.scalar(&encoder.Doc{Type: t}, path)
+	if node == nil && err == nil {
+		node = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}
+	}
+
+	return node, err
+}
+
+// scalar asks for a scalar value offering valid values as choices and the
+// default or the first example as the default answer.
+//
+//nolint:gocyclo
+func (w *Wizard) scalar(field *encoder.Doc, path string) (*yaml.Node, error) {
+	choices := make([]string, 0, len(field.Values)+len(field.EnumFields))
+	choices = append(choices, field.Values...)
+	choices = append(choices, field.EnumFields...)
+
+	for i, choice := range choices {
+		w.printf("  %d) %s\n", i+1, choice)
+	}
+
+	def := defaultValue(field)
+
+	prompt := path + ": "
+	if def != "" {
+		prompt = fmt.Sprintf("%s [%s]: ", path, def)
+	}
+
+	for {
+		answer := w.ask(prompt)
+		if answer == "" {
+			answer = def
+		}
+
+		if answer == "" {
+			if !field.Required {
+				return nil, nil
+			}
+
+			if w.eof {
+				return nil, fmt.Errorf("%s: value is required", path)
+			}
+
+			w.printf("%s is required\n", path)
+
+			continue
+		}
+
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(choices) && !contains(choices, answer) {
+			answer = choices[n-1]
+		}
+
+		if len(choices) > 0 && !contains(choices, answer) {
+			if w.eof {
+				return nil, fmt.Errorf("%s: invalid choice %q", path, answer)
+			}
+
+			w.printf("invalid choice %q\n", answer)
+
+			continue
+		}
+
+		node, err := scalarNode(field.Type, answer)
+		if err == nil {
+			return node, nil
+		}
+
+		if w.eof {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		w.printf("invalid value: %s\n", err)
+	}
+}
+
+func (w *Wizard) confirm(prompt string) bool {
+	switch strings.ToLower(w.ask(prompt)) {
+	case "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
+
+// ask prints the prompt and reads a single line answer.
+func (w *Wizard) ask(prompt string) string {
+	w.printf("%s", prompt)
+
+	if w.eof {
+		w.printf("\n")
+
+		return ""
+	}
+
+	line, err := w.in.ReadString('\n')
+	if err != nil {
+		w.eof = true
+
+		if errors.Is(err, io.EOF) && line != "" {
+			w.printf("\n")
+		}
+	}
+
+	return strings.TrimSpace(line)
+}
+
+func (w *Wizard) printf(format string, args ...interface{}) {
+	fmt.Fprintf(w.out, format, args...)
+}
+
+// isScalar checks if the type is neither a container nor a documented struct.
+func (w *Wizard) isScalar(t string) bool {
+	t = strings.TrimPrefix(t, "*")
+
+	kind, elem := encoder.SplitFieldType(t)
+
+	return kind == encoder.FieldTypeValue && w.docs.Lookup(elem) == nil
+}
+
+// scalarNode parses the answer according to the builtin type.
+func scalarNode(t, answer string) (*yaml.Node, error) {
+	var value interface{} = answer
+
+	var err error
+
+	t = strings.TrimPrefix(t, "*")
+
+	switch t {
+	case "bool":
+		value, err = strconv.ParseBool(answer)
+	case "int", "int8", "int16", "int32", "int64":
+		value, err = strconv.ParseInt(answer, 0, 64)
+	case "uint", "uint8", "uint16", "uint32", "uint64":
+		value, err = strconv.ParseUint(answer, 0, 64)
+	case "float32", "float64":
+		value, err = strconv.ParseFloat(answer, 64)
+	case "interface{}":
+		// any yaml value is accepted, e.g. 10, true or [a, b]
+		doc := &yaml.Node{}
+		if err = yaml.Unmarshal([]byte(answer), doc); err == nil && len(doc.Content) > 0 {
+			return doc.Content[0], nil
+		}
+
+		return nil, fmt.Errorf("expected %s", t)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("expected %s", t)
+	}
+
+	node := &yaml.Node{}
+
+	return node, node.Encode(value)
+}
+
+// defaultValue returns the documented default or the first scalar example, examples
+// of sensitive fields are not used.
+func defaultValue(field *encoder.Doc) string {
+	if field.Default != "" || field.Sensitive {
+		return field.Default
+	}
+
+	for _, example := range field.Examples {
+		switch v := example.GetValue().(type) {
+		case string, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+			return fmt.Sprint(v)
+		case *yaml.Node:
+			if v.Kind == yaml.ScalarNode {
+				return v.Value
+			}
+		}
+	}
+
+	return ""
+}
+
+func exampleName(example *encoder.Example, i int) string {
+	if example.GetName() != "" {
+		return example.GetName()
+	}
+
+	return fmt.Sprintf("Example %d", i+1)
+}
+
+func stringNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
+
+func indent(text, prefix string) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(prefix+line, " ")
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,181 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package wizard
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	yaml "gopkg.in/yaml.v3"
+
+	"github.com/projectdiscovery/yamldoc-go/encoder"
+)
+
+type Config struct {
+	Name    string            `yaml:"name"`
+	Key     string            `yaml:"key"`
+	Jobs    []Job             `yaml:"jobs,omitempty"`
+	Options *Options          `yaml:"options,omitempty"`
+	Labels  map[string]string `yaml:"labels,omitempty"`
+}
+
+type Job struct {
+	Name    string `yaml:"name"`
+	Enabled bool   `yaml:"enabled"`
+}
+
+type Options struct {
+	BulkSize int `yaml:"bulk-size"`
+}
+
+var configDoc, jobDoc, optionsDoc encoder.Doc
+
+func init() {
+	configDoc.Type = "Config"
+	configDoc.Comments[encoder.LineComment] = "Config is the scanner config."
+	configDoc.Fields = []encoder.Doc{
+		{Name: "name", Type: "string", Description: "Name of the config.", Required: true},
+		{Name: "key", Type: "string", Description: "Key of the config.", Values: []string{"dns", "http"}, Default: "dns"},
+		{Name: "jobs", Type: "[]Job", Description: "Jobs to run."},
+		{Name: "options", Type: "Options", Description: "Options of the scanner."},
+		{Name: "labels", Type: "map[string]string", Description: "Labels of the config."},
+	}
+	configDoc.Fields[0].Comments[encoder.LineComment] = "Name of the config."
+	configDoc.Fields[0].AddExample("Name Example", "internet-wide")
+	configDoc.Fields[3].Comments[encoder.LineComment] = "Options of the scanner."
+	configDoc.Fields[3].AddExample("Small", &Options{BulkSize: 10})
+
+	jobDoc.Type = "Job"
+	jobDoc.Fields = []encoder.Doc{
+		{Name: "name", Type: "string", Description: "Name of the job."},
+		{Name: "enabled", Type: "bool", Description: "Enables the job."},
+	}
+
+	optionsDoc.Type = "Options"
+	optionsDoc.Fields = []encoder.Doc{
+		{Name: "bulk-size", Type: "int", Description: "Bulk size."},
+	}
+}
+
+func (Config) Doc() *encoder.Doc {
+	return &configDoc
+}
+
+func (Job) Doc() *encoder.Doc {
+	return &jobDoc
+}
+
+func (Options) Doc() *encoder.Doc {
+	return &optionsDoc
+}
+
+func TestGenerate(t *testing.T) {
+	answers := strings.Join([]string{
+		"",      // name: use the example
+		"3",     // key: invalid choice
+		"2",     // key: http
+		"y",     // configure jobs
+		"y",     // add a job
+		"first", // jobs[0].name
+		"maybe", // jobs[0].enabled: invalid bool
+		"true",  // jobs[0].enabled
+		"n",     // no more jobs
+		"1",     // options: use the example
+		"y",     // configure labels
+		"team",  // labels key
+		"red",   // labels.team
+		"",      // no more labels
+	}, "\n") + "\n"
+
+	var out bytes.Buffer
+
+	var config Config
+
+	data, err := Generate(strings.NewReader(answers), &out, &config)
+	require.NoError(t, err)
+
+	assert.Equal(t, Config{
+		Name:    "internet-wide",
+		Key:     "http",
+		Jobs:    []Job{{Name: "first", Enabled: true}},
+		Options: &Options{BulkSize: 10},
+		Labels:  map[string]string{"team": "red"},
+	}, config)
+
+	assert.Contains(t, string(data), "name: internet-wide # Name of the config.\n")
+	assert.Contains(t, string(data), "# Options of the scanner.\noptions:\n")
+
+	prompts := out.String()
+	assert.Contains(t, prompts, "name (string)\n  Name of the config.\nname [internet-wide]: ")
+	assert.Contains(t, prompts, "  1) dns\n  2) http\nkey [dns]: invalid choice \"3\"\n")
+	assert.Contains(t, prompts, "jobs[0].enabled: invalid value: expected bool\n")
+	assert.Contains(t, prompts, "  1) Small\nConfigure options? [y/N or example number]: ")
+}
+
+func TestEncodeDefaults(t *testing.T) {
+	w, err := New(strings.NewReader(""), &bytes.Buffer{}, "Config", encoder.CollectDocs(Config{}))
+	require.NoError(t, err)
+
+	data, err := w.Encode()
+	require.NoError(t, err)
+
+	assert.Equal(t, "name: internet-wide # Name of the config.\nkey: dns\n", string(data))
+}
+
+func TestRequired(t *testing.T) {
+	fd := &encoder.FileDoc{Structs: []*encoder.Doc{{
+		Type:   "Config",
+		Fields: []encoder.Doc{{Name: "name", Type: "string", Required: true}},
+	}}}
+
+	w, err := New(strings.NewReader("\n"), &bytes.Buffer{}, "Config", fd)
+	require.NoError(t, err)
+
+	_, err = w.Run()
+	assert.EqualError(t, err, "name: value is required")
+
+	_, err = New(strings.NewReader(""), &bytes.Buffer{}, "Unknown", fd)
+	assert.EqualError(t, err, `type "Unknown" is not documented`)
+}
+
+func TestSensitiveExamples(t *testing.T) {
+	field := &encoder.Doc{Name: "token", Type: "string", Sensitive: true}
+	field.AddExample("Token Example", "secret-token")
+
+	assert.Empty(t, defaultValue(field))
+
+	field.Sensitive = false
+	assert.Equal(t, "secret-token", defaultValue(field))
+}
+
+func TestScalarNode(t *testing.T) {
+	for _, test := range []struct {
+		t, answer, expected string
+	}{
+		{t: "int", answer: "10", expected: "10\n"},
+		{t: "*uint8", answer: "0x10", expected: "16\n"},
+		{t: "float64", answer: "1.5", expected: "1.5\n"},
+		{t: "string", answer: "10", expected: "\"10\"\n"},
+		{t: "interface{}", answer: "hello", expected: "hello\n"},
+		{t: "interface{}", answer: "10", expected: "10\n"},
+		{t: "interface{}", answer: "[a, b]", expected: "[a, b]\n"},
+	} {
+		node, err := scalarNode(test.t, test.answer)
+		require.NoError(t, err, test.t)
+
+		data, err := yaml.Marshal(node)
+		require.NoError(t, err, test.t)
+		assert.Equal(t, test.expected, string(data), test.t)
+	}
+
+	_, err := scalarNode("int", "many")
+	assert.EqualError(t, err, "expected int")
+
+	_, err = scalarNode("interface{}", "[a")
+	assert.EqualError(t, err, "expected interface{}")
+}
@@ -6,10 +6,10 @@ import (
 )
 
 var (
-	exampleProvider = map[string]interface{}{
-		"apollo-digitalocean": map[string]interface{}{
-			"api-key":      ksuid.New().String(),
-			"access-token": ksuid.New().String(),
+	exampleProvider = map[string]*Provider{
+		"apollo-digitalocean": {
+			APIKey:      ksuid.New().String(),
+			AccessToken: ksuid.New().String(),
 		},
 	}
 
@@ -52,18 +52,32 @@ type Job struct {
 	// examples:
 	//   - name: Providers Example
 	//     value: exampleProvider
-	Providers map[string]map[string]string `yaml:"providers" json:"-"`
+	Providers map[string]*Provider `yaml:"providers" json:"-"`
 	// description: |
 	//   InternalOptions contains internal configuration options for scheduler
 	// examples:
 	//   - name: InternalOptions Example
 	//     value: exampleInternalOptions
+	//   - name: InternalOptions File Example
+	//     file: testdata/internal-options.yaml
 	InternalOptions *InternalOptions `yaml:"internal-options" json:"internal-options"`
 	// docgen:nodoc
 	// you can also skip members that are not part of the yaml file
 	NotConfiguredByYaml string
 }
 
+// Provider contains the credentials of an infrastructure provider.
+type Provider struct {
+	// description: |
+	//   APIKey is the API key of the provider.
+	// sensitive: true
+	APIKey string `yaml:"api-key" json:"api-key"`
+	// description: |
+	//   AccessToken is the access token of the provider.
+	// sensitive: true
+	AccessToken string `yaml:"access-token" json:"access-token"`
+}
+
 // InternalOptions contains internal configuration options for scheduler
 type InternalOptions struct {
 	// description: |
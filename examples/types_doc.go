@@ -10,6 +10,7 @@ import (
 
 var (
 	JobDoc             encoder.Doc
+	ProviderDoc        encoder.Doc
 	InternalOptionsDoc encoder.Doc
 )
 
@@ -43,7 +44,7 @@ func init() {
 
 	JobDoc.Fields[2].AddExample("Description Example", "Runs masscan on port 443 followed by httpx")
 	JobDoc.Fields[3].Name = "providers"
-	JobDoc.Fields[3].Type = "map[string]map[string]string"
+	JobDoc.Fields[3].Type = "map[string]Provider"
 	JobDoc.Fields[3].Note = ""
 	JobDoc.Fields[3].Description = "Providers contains a list of infrastructure providers\nfor the current scan."
 	JobDoc.Fields[3].Comments[encoder.LineComment] = "Providers contains a list of infrastructure providers"
@@ -57,11 +58,42 @@ func init() {
 
 	JobDoc.Fields[4].AddExample("InternalOptions Example", exampleInternalOptions)
 
+	JobDoc.Fields[4].AddExample("InternalOptions File Example", encoder.MustDecodeExample(new(InternalOptions), `bulk-size: 5000
+scheduling-workers: 50
+`))
+
+	ProviderDoc.Type = "Provider"
+	ProviderDoc.Comments[encoder.LineComment] = "Provider contains the credentials of an infrastructure provider."
+	ProviderDoc.Description = "Provider contains the credentials of an infrastructure provider."
+	ProviderDoc.AppearsIn = []encoder.Appearance{
+		{
+			TypeName:  "Job",
+			FieldName: "providers",
+		},
+	}
+	ProviderDoc.Fields = make([]encoder.Doc, 2)
+	ProviderDoc.Fields[0].Name = "api-key"
+	ProviderDoc.Fields[0].Type = "string"
+	ProviderDoc.Fields[0].Note = ""
+	ProviderDoc.Fields[0].Description = "APIKey is the API key of the provider."
+	ProviderDoc.Fields[0].Comments[encoder.LineComment] = "APIKey is the API key of the provider."
+	ProviderDoc.Fields[0].Sensitive = true
+	ProviderDoc.Fields[1].Name = "access-token"
+	ProviderDoc.Fields[1].Type = "string"
+	ProviderDoc.Fields[1].Note = ""
+	ProviderDoc.Fields[1].Description = "AccessToken is the access token of the provider."
+	ProviderDoc.Fields[1].Comments[encoder.LineComment] = "AccessToken is the access token of the provider."
+	ProviderDoc.Fields[1].Sensitive = true
+
 	InternalOptionsDoc.Type = "InternalOptions"
 	InternalOptionsDoc.Comments[encoder.LineComment] = "InternalOptions contains internal configuration options for scheduler"
 	InternalOptionsDoc.Description = "InternalOptions contains internal configuration options for scheduler"
 
 	InternalOptionsDoc.AddExample("InternalOptions Example", exampleInternalOptions)
+
+	InternalOptionsDoc.AddExample("InternalOptions File Example", encoder.MustDecodeExample(new(InternalOptions), `bulk-size: 5000
+scheduling-workers: 50
+`))
 	InternalOptionsDoc.AppearsIn = []encoder.Appearance{
 		{
 			TypeName:  "Job",
@@ -89,6 +121,10 @@ func (Job) Doc() *encoder.Doc {
 	return &JobDoc
 }
 
+func (Provider) Doc() *encoder.Doc {
+	return &ProviderDoc
+}
+
 func (InternalOptions) Doc() *encoder.Doc {
 	return &InternalOptionsDoc
 }
@@ -100,6 +136,7 @@ func GetConfigurationDoc() *encoder.FileDoc {
 		Description: "go:generate docgen types.go types_doc.go Configuration\n",
 		Structs: []*encoder.Doc{
 			&JobDoc,
+			&ProviderDoc,
 			&InternalOptionsDoc,
 		},
 	}